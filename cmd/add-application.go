@@ -3,12 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
 	"github.com/codeready-toolchain/sandbox-argocd/pkg/client"
 
-	"github.com/agnivade/levenshtein"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -18,9 +16,11 @@ func NewAddAppCmd() *cobra.Command {
 	var pathToApps string
 	var repositoryURL string
 	var targetRevision string
+	var rewriteRepos []string
+	var rewriteReposFile string
 
 	cmd := &cobra.Command{
-		Use:   "add-application <name> --apps=<path/to/apps> --repo-url=<url> --target-revision=<revision> --kubeconfig=<path/to/kubeconfig>",
+		Use:   "add-application <name> --apps=<path/to/apps> (--repo-url=<url> --target-revision=<revision> | --rewrite-repo=<from>=<to>[@<revision>] | --rewrite-repo-file=<path>) --kubeconfig=<path/to/kubeconfig>",
 		Short: "Add an Application or ApplicationSet",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -29,53 +29,54 @@ func NewAddAppCmd() *cobra.Command {
 			if verbose {
 				logger.SetLevel(log.DebugLevel)
 			}
-			cl, err := client.NewFromConfig(kubeconfig)
-			if err != nil {
-				logger.Errorf("error occurred: %s", err.Error())
+			if (repositoryURL == "") != (targetRevision == "") {
+				logger.Error("'--repo-url' and '--target-revision' must be specified together")
 				os.Exit(1)
 			}
 			afs := afero.Afero{
 				Fs: afero.NewOsFs(),
 			}
+			rewrites, err := loadRepoRewrites(afs, rewriteRepos, rewriteReposFile)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if repositoryURL == "" && len(rewrites) == 0 {
+				logger.Error("either '--repo-url' and '--target-revision', or '--rewrite-repo' or '--rewrite-repo-file' must be specified")
+				os.Exit(1)
+			}
+			cl, err := client.NewFromConfig(kubeconfig)
+			if err != nil {
+				logger.Errorf("error occurred: %s", err.Error())
+				os.Exit(1)
+			}
 			apps, appsets, err := applications.ListApplications(logger, afs, pathToApps)
 			if err != nil {
 				return err
 			}
 			for _, app := range apps {
 				if app.Name == args[0] {
-					app.Spec.Source.RepoURL = repositoryURL
-					app.Spec.Source.TargetRevision = targetRevision
+					if repositoryURL != "" {
+						applications.OverrideRepository(&app.Spec, repositoryURL, targetRevision)
+					}
+					rewrites.Application(app)
 					return applications.CreateApplication(cmd.Context(), logger, cl, app)
 				}
 			}
 
 			for _, appset := range appsets {
 				if appset.Name == args[0] {
-					appset.Spec.Template.Spec.Source.RepoURL = repositoryURL
-					appset.Spec.Template.Spec.Source.TargetRevision = targetRevision
+					if repositoryURL != "" {
+						applications.OverrideRepository(&appset.Spec.Template.Spec, repositoryURL, targetRevision)
+					}
+					rewrites.ApplicationSet(appset)
 					return applications.CreateApplicationSet(cmd.Context(), logger, cl, appset)
 				}
 			}
 			logger.Errorf("🤷 unable to find the '%s' Argo CD Application/ApplicationSet", args[0])
 
 			// in this case, suggest the closest apps/appsets
-			suggestions := []string{}
-			threshold := 4
-			for _, app := range apps {
-				if distance := levenshtein.ComputeDistance(args[0], app.Name); distance < threshold {
-					suggestions = append(suggestions, app.Name)
-				}
-			}
-			for _, appset := range appsets {
-				if distance := levenshtein.ComputeDistance(args[0], appset.Name); distance < threshold {
-					suggestions = append(suggestions, appset.Name)
-				}
-			}
-			if len(suggestions) > 0 {
-				logger.Infof("🤔 did you mean: %s", strings.Join(suggestions, ", "))
-			} else {
-				logger.Info("🤨 no similar Application or ApplicationSet")
-			}
+			logSuggestions(logger, args[0], apps, appsets)
 
 			return nil
 		},
@@ -85,16 +86,10 @@ func NewAddAppCmd() *cobra.Command {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}
-	cmd.Flags().StringVar(&repositoryURL, "repo-url", "", "Application's Repository URL (overridding the .spec value)")
-	if err := cmd.MarkFlagRequired("repo-url"); err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
-	}
+	cmd.Flags().StringVar(&repositoryURL, "repo-url", "", "Application's Repository URL (overridding the .spec value, in all the sources except the Helm charts)")
 	cmd.Flags().StringVar(&targetRevision, "target-revision", "", "Application's Target revision (overridding the .spec value)")
-	if err := cmd.MarkFlagRequired("target-revision"); err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
-	}
+	cmd.Flags().StringArrayVar(&rewriteRepos, "rewrite-repo", []string{}, "repository rewrite applied on the sources which refer to the '<from>' repository (eg: 'https://github.com/org/repo=https://github.com/me/repo@my-branch', can be repeated)")
+	cmd.Flags().StringVar(&rewriteReposFile, "rewrite-repo-file", "", "path to the YAML file with a list of repository rewrites (eg: '[{from: <url>, to: <url>, targetRevision: <revision>}]')")
 
 	return cmd
 }
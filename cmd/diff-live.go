@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/client"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewDiffLiveCmd() *cobra.Command {
+	var pathToApps string
+	var baseDir string
+
+	cmd := &cobra.Command{
+		Use:   "diff-live <name> --apps=<path/to/apps> [--base-dir=<path/to/repository>] --kubeconfig=<path/to/kubeconfig>",
+		Short: "Show the differences between the manifests of an Application rendered from the local sources and the objects in the cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// diffs are written in stdout, so logs go to stderr
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			apps, appsets, err := applications.ListApplications(logger, afs, pathToApps)
+			if err != nil {
+				return err
+			}
+			candidates := append([]*argocdv1alpha1.Application{}, apps...)
+			for _, appset := range appsets {
+				generated, err := applications.GenerateApplications(appset)
+				if err != nil {
+					return err
+				}
+				candidates = append(candidates, generated...)
+			}
+			for _, app := range candidates {
+				if app.Name != args[0] {
+					continue
+				}
+				fsys, err := validation.NewInMemoryFS(logger, afs, baseDir)
+				if err != nil {
+					return err
+				}
+				objs, err := render.Application(logger, fsys, baseDir, app)
+				if err != nil {
+					return err
+				}
+				cl, err := client.NewFromConfig(kubeconfig)
+				if err != nil {
+					return err
+				}
+				objDiffs, err := diff.Live(cmd.Context(), logger, cl, app, objs)
+				if err != nil {
+					return err
+				}
+				status := diff.Modified
+				if len(objDiffs) == 0 {
+					status = diff.Unchanged
+				}
+				return diff.WriteText(cmd.OutOrStdout(), []diff.ApplicationDiff{
+					{
+						Application: app.Name,
+						Status:      status,
+						Objects:     objDiffs,
+					},
+				})
+			}
+			logger.Errorf("🤷 unable to find the '%s' Argo CD Application", args[0])
+			logSuggestions(logger, args[0], apps, appsets)
+			os.Exit(1)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&pathToApps, "apps", "a", "", "Path to ArgoCD Application and ApplicationSets")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (to resolve the source path of the Application)")
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+func NewDiffRenderCmd() *cobra.Command {
+	var apps, components []string
+	var baseDir string
+	var base, head string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "diff-render --base <git-ref> [--head <git-ref>] --apps apps-of-apps,apps --components components [--base-dir=<path/to/repository>] [--output=text|markdown|json]",
+		Short: "Show the differences in the manifests rendered by the Applications affected by the changes between two Git revisions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// diffs are written in stdout, so logs go to stderr
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.WarnLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			diffs, err := diff.Revisions(logger, baseDir, base, head, apps, components)
+			if err != nil {
+				return err
+			}
+			switch output {
+			case "text":
+				return diff.WriteText(cmd.OutOrStdout(), diffs)
+			case "markdown":
+				return diff.WriteMarkdown(cmd.OutOrStdout(), base, head, diffs)
+			case "json":
+				data, err := json.MarshalIndent(diffs, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			default:
+				return fmt.Errorf("unsupported output: '%s' (expected 'text', 'markdown' or 'json')", output)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&base, "base", "", "Git revision to compare from (branch, tag, commit, etc.)")
+	if err := cmd.MarkFlagRequired("base"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&head, "head", "HEAD", "Git revision to compare to (branch, tag, commit, etc.)")
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringSliceVar(&components, "components", []string{}, "path(s) to the components (comma-separated, relative to '--base-dir')")
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: 'text', 'markdown' (eg: for a pull request comment) or 'json'")
+	return cmd
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/bundle"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewExportCmd() *cobra.Command {
+	var apps []string
+	var baseDir string
+	var clusterInventory string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export --apps=<path/to/apps> --output=<path/to/bundle>[.tar.gz] [--base-dir=<path/to/repository>] [--clusters=<path/to/clusters>]",
+		Short: "Export the manifests rendered by the Applications from the local sources, grouped by destination cluster and namespace",
+		Args:  cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			var inventory clusters.Inventory
+			if clusterInventory != "" {
+				// resolve the name of the destination clusters which are referred to by their server URL
+				var err error
+				if inventory, err = clusters.Load(logger, afs, filepath.Join(baseDir, clusterInventory)); err != nil {
+					logger.Error("failed to load cluster inventory", "path", clusterInventory, "err", err)
+					os.Exit(1)
+				}
+			}
+			b, err := bundle.Export(logger, afs, baseDir, apps, inventory)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := b.Write(afs, output); err != nil {
+				logger.Error("failed to write the bundle", "path", output, "err", err)
+				os.Exit(1)
+			}
+			logger.Info("📦 exported bundle", "path", output, "objects", len(b.Index.Entries), "clusters", b.Clusters())
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "directory in which the bundle is written (or gzipped tarball, if the path ends with '.tar.gz' or '.tgz')")
+	if err := cmd.MarkFlagRequired("output"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().StringVar(&clusterInventory, "clusters", "", "path to the cluster inventory (Argo CD cluster Secrets or list of clusters) used to name the destination clusters (relative to '--base-dir')")
+	return cmd
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/format"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewFmtCmd() *cobra.Command {
+	var apps, components []string
+	var baseDir string
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "fmt --apps=<path/to/apps> --components=<path/to/components> [--base-dir=<path/to/repository>] [--check]",
+		Short: "Rewrite the kustomization files and the Applications in a canonical form",
+		Args:  cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			logger := log.New(cmd.OutOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			results, err := format.Files(logger, afs, baseDir, apps, components, !check)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			changed := 0
+			for _, r := range results {
+				for _, w := range r.Warnings {
+					logger.Warn(w, "path", r.Path)
+				}
+				if !r.Changed {
+					continue
+				}
+				changed++
+				if check {
+					logger.Error("❌ file is not formatted", "path", r.Path)
+				} else {
+					logger.Info("📝 formatted", "path", r.Path)
+				}
+			}
+			switch {
+			case check && changed > 0:
+				logger.Errorf("%d file(s) not formatted (run 'fmt' without '--check' to fix them)", changed)
+				os.Exit(1)
+			case check:
+				logger.Info("👍 all files are formatted")
+			default:
+				logger.Infof("✨ formatted %d file(s)", changed)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringSliceVar(&components, "components", []string{}, "path(s) to the components (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("components"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().BoolVar(&check, "check", false, "do not rewrite the files but fail if some of them are not formatted (eg: in CI)")
+	return cmd
+}
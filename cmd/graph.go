@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/dependencies"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewGraphCmd() *cobra.Command {
+	var apps, components []string
+	var baseDir string
+	var format string
+	var focus string
+
+	cmd := &cobra.Command{
+		Use:   "graph --apps apps-of-apps,apps --components components [--base-dir=<path/to/repository>] [--format=dot|mermaid|json] [--focus=<path/to/dir>]",
+		Short: "Print the dependency graph of the Applications, ApplicationSets and Kustomizations",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// graph is written in stdout, so logs go to stderr
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.WarnLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			graph, err := dependencies.Build(logger, afs, baseDir, apps, components)
+			if err != nil {
+				return err
+			}
+			if focus != "" {
+				// only keep what uses the given directory
+				graph = graph.Dependents(dependencies.ID(dependencies.Directory, "", filepath.Clean(focus)))
+			}
+			return graph.Write(cmd.OutOrStdout(), format)
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringSliceVar(&components, "components", []string{}, "path(s) to the components (comma-separated, relative to '--base-dir')")
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository")
+	cmd.Flags().StringVarP(&format, "format", "f", dependencies.DOT, "output format: 'dot', 'mermaid' or 'json'")
+	cmd.Flags().StringVar(&focus, "focus", "", "only show the Applications, ApplicationSets and Kustomizations which use the given directory (relative to '--base-dir')")
+	return cmd
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/dependencies"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewImpactCmd() *cobra.Command {
+	var apps, components []string
+	var baseDir string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "impact <path>... --apps apps-of-apps,apps --components components [--base-dir=<path/to/repository>] [--output=text|json]",
+		Short: "List the Applications (and their destination) affected by a change in the given files",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// impacts are written in stdout, so logs go to stderr
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.WarnLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			impacts, err := dependencies.Impacted(logger, afs, baseDir, apps, components, args...)
+			if err != nil {
+				return err
+			}
+			switch output {
+			case "json":
+				data, err := json.MarshalIndent(impacts, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			case "text":
+				if len(impacts) == 0 {
+					_, err := fmt.Fprintln(cmd.OutOrStdout(), "no Application is affected")
+					return err
+				}
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "APPLICATION\tAPPLICATIONSET\tFILE\tCLUSTER\tNAMESPACE\tPATHS")
+				for _, i := range impacts {
+					cluster := i.Server
+					if cluster == "" {
+						cluster = i.Cluster
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", i.Application, orNone(i.ApplicationSet), i.File, orNone(cluster), orNone(i.Namespace), strings.Join(i.Paths, ","))
+				}
+				return w.Flush()
+			default:
+				return fmt.Errorf("unsupported output: '%s' (expected 'text' or 'json')", output)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringSliceVar(&components, "components", []string{}, "path(s) to the components (comma-separated, relative to '--base-dir')")
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: 'text' or 'json'")
+	return cmd
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/bundle"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/client"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewImportCmd() *cobra.Command {
+	var cluster string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import <path/to/bundle>[.tar.gz] [--cluster=<name>] [--dry-run] --kubeconfig=<path/to/kubeconfig>",
+		Short: "Apply the manifests of a bundle created by the 'export' command, after verifying their checksums",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.New(cmd.OutOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			b, err := bundle.Read(afs, args[0])
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			logger.Info("👍 verified bundle", "path", args[0], "objects", len(b.Index.Entries))
+			names := b.Clusters()
+			if cluster == "" {
+				if len(names) != 1 {
+					logger.Errorf("the bundle contains the manifests of %d clusters, use '--cluster' to select one of them: %s", len(names), strings.Join(names, ", "))
+					os.Exit(1)
+				}
+				cluster = names[0]
+			}
+			objs, err := b.Objects(cluster)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if len(objs) == 0 {
+				logger.Errorf("🤷 no manifest for cluster '%s' in the bundle (available: %s)", cluster, strings.Join(names, ", "))
+				os.Exit(1)
+			}
+			cl, err := client.NewFromConfig(kubeconfig)
+			if err != nil {
+				logger.Errorf("error occurred: %s", err.Error())
+				os.Exit(1)
+			}
+			if err := bundle.Apply(cmd.Context(), logger, cl, objs, dryRun); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if dryRun {
+				logger.Infof("✨ applied %d object(s) of cluster '%s' (dry-run)", len(objs), cluster)
+			} else {
+				logger.Infof("✨ applied %d object(s) of cluster '%s'", len(objs), cluster)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "name of the cluster whose manifests are applied (required if the bundle contains the manifests of several clusters)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "send the requests in dry-run mode (the objects are validated by the API server but not persisted)")
+	return cmd
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/migrate"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewMigrateKustomizeCmd() *cobra.Command {
+	var apps, components []string
+	var baseDir string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-kustomize --apps=<path/to/apps> --components=<path/to/components> [--base-dir=<path/to/repository>] [--dry-run]",
+		Short: "Migrate the deprecated fields of the kustomization files (verified by comparing the output of 'kustomize build')",
+		Args:  cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			logger := log.New(cmd.OutOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			migrations, err := migrate.Kustomizations(logger, afs, baseDir, append(apps, components...), !dryRun)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			migrated, refused := 0, 0
+			for _, m := range migrations {
+				if m.Err != nil {
+					refused++
+					logger.Warn("⚠️  migration refused", "path", m.Path, "field", m.Field, "reason", m.Err)
+					continue
+				}
+				migrated++
+				logger.Info("🔧 migrated", "path", m.Path, "field", m.Field, "replacement", m.Replacement)
+			}
+			switch {
+			case len(migrations) == 0:
+				logger.Info("👍 no deprecated field to migrate")
+			case dryRun:
+				logger.Infof("✨ %d field(s) can be migrated, %d migration(s) refused (dry-run)", migrated, refused)
+			default:
+				logger.Infof("✨ migrated %d field(s), %d migration(s) refused", migrated, refused)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringSliceVar(&components, "components", []string{}, "path(s) to the components (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("components"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "verify the migrations without rewriting the files")
+	return cmd
+}
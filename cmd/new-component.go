@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/scaffold"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewNewComponentCmd() *cobra.Command {
+	var apps, components string
+	var baseDir string
+	var opts scaffold.Options
+	var namespace, project, repoURL, targetRevision string
+
+	cmd := &cobra.Command{
+		Use:   "new-component <name> --apps=<path/to/apps> --components=<path/to/components> [--base-dir=<path/to/repository>] [--template=<name>] [--applicationset] [--set key=value]",
+		Short: "Create a new component and its Application (or ApplicationSet) from a template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := log.New(cmd.OutOrStdout())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			if opts.Values == nil {
+				opts.Values = map[string]string{}
+			}
+			for k, v := range map[string]string{
+				"Namespace":      namespace,
+				"Project":        project,
+				"RepoURL":        repoURL,
+				"TargetRevision": targetRevision,
+			} {
+				if v != "" {
+					opts.Values[k] = v
+				}
+			}
+			files, err := scaffold.NewComponent(logger, afs, baseDir, apps, components, args[0], opts)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				logger.Info("📝 created or updated", "path", f)
+			}
+			logger.Infof("✨ created component '%s'", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&apps, "apps", "", "path to the directory in which the Application (or ApplicationSet) is created (relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&components, "components", "", "path to the directory in which the component is created (relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("components"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().StringVar(&opts.Template, "template", scaffold.DefaultTemplate, "name of the template (a repo-local template in '--templates-dir' or a built-in template: 'kustomize' or 'deployment')")
+	cmd.Flags().StringVar(&opts.TemplatesDir, "templates-dir", scaffold.DefaultTemplatesDir, "directory of the repo-local templates (relative to '--base-dir')")
+	cmd.Flags().BoolVar(&opts.ApplicationSet, "applicationset", false, "create an ApplicationSet instead of an Application")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "destination namespace (default to the name of the component)")
+	cmd.Flags().StringVar(&project, "project", "", "AppProject of the Application (default to 'default')")
+	cmd.Flags().StringVar(&repoURL, "repo-url", "", "repository URL of the source (default to the most common repository URL in '--apps')")
+	cmd.Flags().StringVar(&targetRevision, "target-revision", "", "target revision of the source (default to the most common target revision in '--apps')")
+	cmd.Flags().StringToStringVar(&opts.Values, "set", map[string]string{}, "additional values used in the templates (eg: '--set Image=quay.io/org/name:v1.0.0')")
+	return cmd
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/promote"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewPromoteCmd() *cobra.Command {
+	var components []string
+	var baseDir string
+	var from, to string
+	var opts promote.Options
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "promote <component> --from=<env> --to=<env> --components=<path/to/components> [--base-dir=<path/to/repository>] [--images=<name>,...] [--patches=<path>,...] [--dry-run]",
+		Short: "Promote the images and patches of a component from an overlay to another",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// the rendered diff is written to stdout, the logs to stderr
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			result, err := promote.Component(logger, afs, baseDir, components, args[0], from, to, opts, !dryRun)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			logger.Info("🚀 promoting component", "name", args[0], "from", result.Source, "to", result.Target)
+			for _, d := range result.Diffs {
+				fmt.Fprint(cmd.OutOrStdout(), d.Diff)
+			}
+			if len(result.Diffs) == 0 {
+				logger.Info("no change in the objects rendered by the target overlay")
+			}
+			for _, f := range result.Files {
+				if dryRun {
+					logger.Info("📝 would update", "path", f)
+				} else {
+					logger.Info("📝 updated", "path", f)
+				}
+			}
+			if len(result.Files) == 0 {
+				logger.Info("👍 nothing to promote")
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&components, "components", []string{}, "path(s) to the components (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("components"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&from, "from", "", "environment of the source overlay (in the 'overlays/<env>' or '<env>' directory of the component)")
+	if err := cmd.MarkFlagRequired("from"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&to, "to", "", "environment of the target overlay (in the 'overlays/<env>' or '<env>' directory of the component)")
+	if err := cmd.MarkFlagRequired("to"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().StringSliceVar(&opts.Images, "images", []string{}, "name(s) of the images to promote (comma-separated, all images if no image and no patch is selected)")
+	cmd.Flags().StringSliceVar(&opts.Patches, "patches", []string{}, "path(s) of the patches to promote, as declared in the 'patches' of the source overlay (comma-separated)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the differences of the rendered objects without writing the files")
+	return cmd
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewRenderCmd() *cobra.Command {
+	var pathToApps string
+	var baseDir string
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "render <name> --apps=<path/to/apps> [--base-dir=<path/to/repository>] [--output-dir=<path/to/output>]",
+		Short: "Render the manifests of an Application (or of an Application generated by an ApplicationSet) from the local sources",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// manifests are written in stdout, so logs go to stderr
+			logger := log.New(cmd.ErrOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			apps, appsets, err := applications.ListApplications(logger, afs, pathToApps)
+			if err != nil {
+				return err
+			}
+			// also look for the Applications generated by the ApplicationSets
+			candidates := append([]*argocdv1alpha1.Application{}, apps...)
+			for _, appset := range appsets {
+				generated, err := applications.GenerateApplications(appset)
+				if err != nil {
+					return err
+				}
+				candidates = append(candidates, generated...)
+			}
+			for _, app := range candidates {
+				if app.Name != args[0] {
+					continue
+				}
+				fsys, err := validation.NewInMemoryFS(logger, afs, baseDir)
+				if err != nil {
+					return err
+				}
+				objs, err := render.Application(logger, fsys, baseDir, app)
+				if err != nil {
+					return err
+				}
+				if outputDir != "" {
+					paths, err := render.WriteFiles(afs, outputDir, objs)
+					if err != nil {
+						return err
+					}
+					logger.Infof("rendered %d object(s) in %s", len(paths), outputDir)
+					return nil
+				}
+				return render.WriteYAML(cmd.OutOrStdout(), objs)
+			}
+			logSuggestions(logger, args[0], candidates, nil)
+			return fmt.Errorf("unable to find the '%s' Argo CD Application", args[0])
+		},
+	}
+	cmd.Flags().StringVarP(&pathToApps, "apps", "a", "", "Path to ArgoCD Application and ApplicationSets")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (to resolve the source path of the Application)")
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "directory in which each object is written in a separate file (instead of stdout)")
+	return cmd
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewRewriteRepoCmd() *cobra.Command {
+	var apps []string
+	var baseDir string
+	var rewriteRepos []string
+	var rewriteReposFile string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rewrite-repo --apps=<path/to/apps> (--rewrite-repo=<from>=<to>[@<revision>]... | --rewrite-repo-file=<path>) [--base-dir=<path/to/repository>] [--dry-run]",
+		Short: "Rewrite the repository URL of the Applications and ApplicationSets which refer to a given repository (eg: to use a fork)",
+		Args:  cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			logger := log.New(cmd.OutOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			rewrites, err := loadRepoRewrites(afs, rewriteRepos, rewriteReposFile)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if len(rewrites) == 0 {
+				logger.Error("either '--rewrite-repo' or '--rewrite-repo-file' must be specified")
+				os.Exit(1)
+			}
+			count := 0
+			for _, p := range apps {
+				changes, err := applications.RewriteRepositories(logger, afs, filepath.Join(baseDir, p), rewrites, !dryRun)
+				if err != nil {
+					logger.Error(err.Error())
+					os.Exit(1)
+				}
+				for _, c := range changes {
+					path, _ := filepath.Rel(baseDir, c.Path)
+					logger.Info("📝 "+c.Kind, "name", c.Name, "path", path, "field", c.Field)
+				}
+				count += len(changes)
+			}
+			switch {
+			case count == 0:
+				logger.Info("🤷 no source refers to the rewritten repositories")
+			case dryRun:
+				logger.Infof("✨ %d field(s) would be changed (dry-run)", count)
+			default:
+				logger.Infof("✨ changed %d field(s)", count)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringArrayVar(&rewriteRepos, "rewrite-repo", []string{}, "repository rewrite applied on the sources which refer to the '<from>' repository (eg: 'https://github.com/org/repo=https://github.com/me/repo@my-branch', can be repeated)")
+	cmd.Flags().StringVar(&rewriteReposFile, "rewrite-repo-file", "", "path to the YAML file with a list of repository rewrites (eg: '[{from: <url>, to: <url>, targetRevision: <revision>}]')")
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the changes without writing the files")
+	return cmd
+}
+
+// returns the repository rewrites of the given `<from>=<to>[@<revision>]` values, followed by the rewrites of the given file (if any),
+// so that the values take precedence over the file
+func loadRepoRewrites(afs afero.Afero, values []string, path string) (applications.RepoRewrites, error) {
+	rewrites := applications.RepoRewrites{}
+	for _, v := range values {
+		r, err := applications.ParseRepoRewrite(v)
+		if err != nil {
+			return nil, err
+		}
+		rewrites = append(rewrites, r)
+	}
+	if path != "" {
+		rs, err := applications.LoadRepoRewrites(afs, path)
+		if err != nil {
+			return nil, err
+		}
+		rewrites = append(rewrites, rs...)
+	}
+	return rewrites, nil
+}
@@ -31,4 +31,17 @@ func init() {
 	rootCmd.AddCommand(NewListAppsCmd())
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewValidateConfigCmd())
+	rootCmd.AddCommand(NewRenderCmd())
+	rootCmd.AddCommand(NewGraphCmd())
+	rootCmd.AddCommand(NewImpactCmd())
+	rootCmd.AddCommand(NewDiffRenderCmd())
+	rootCmd.AddCommand(NewDiffLiveCmd())
+	rootCmd.AddCommand(NewNewComponentCmd())
+	rootCmd.AddCommand(NewFmtCmd())
+	rootCmd.AddCommand(NewMigrateKustomizeCmd())
+	rootCmd.AddCommand(NewPromoteCmd())
+	rootCmd.AddCommand(NewSetRevisionCmd())
+	rootCmd.AddCommand(NewExportCmd())
+	rootCmd.AddCommand(NewImportCmd())
+	rootCmd.AddCommand(NewRewriteRepoCmd())
 }
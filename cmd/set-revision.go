@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func NewSetRevisionCmd() *cobra.Command {
+	var apps []string
+	var baseDir string
+	var from, to string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "set-revision --apps=<path/to/apps> --from=<revision> --to=<revision> [--base-dir=<path/to/repository>] [--dry-run]",
+		Short: "Replace the target revision of the Applications and ApplicationSets",
+		Args:  cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			logger := log.New(cmd.OutOrStderr())
+			logger.SetLevel(log.InfoLevel)
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			afs := afero.Afero{
+				Fs: afero.NewOsFs(),
+			}
+			count := 0
+			for _, p := range apps {
+				changes, err := applications.SetTargetRevision(logger, afs, filepath.Join(baseDir, p), from, to, !dryRun)
+				if err != nil {
+					logger.Error(err.Error())
+					os.Exit(1)
+				}
+				for _, c := range changes {
+					path, _ := filepath.Rel(baseDir, c.Path)
+					logger.Info("📝 "+c.Kind, "name", c.Name, "path", path, "field", c.Field, "from", from, "to", to)
+				}
+				count += len(changes)
+			}
+			switch {
+			case count == 0:
+				logger.Infof("🤷 no source with target revision '%s'", from)
+			case dryRun:
+				logger.Infof("✨ %d target revision(s) would be changed (dry-run)", count)
+			default:
+				logger.Infof("✨ changed %d target revision(s)", count)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&apps, "apps", []string{}, "path(s) to the applications (comma-separated, relative to '--base-dir')")
+	if err := cmd.MarkFlagRequired("apps"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&from, "from", "", "target revision to replace")
+	if err := cmd.MarkFlagRequired("from"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&to, "to", "", "new target revision")
+	if err := cmd.MarkFlagRequired("to"); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cmd.Flags().StringVar(&baseDir, "base-dir", ".", "base directory of the repository (the given paths are relative to it)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the changes without writing the files")
+	return cmd
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/suggest"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+)
+
+// logs the names of the Applications and ApplicationSets which are the closest to the given name
+func logSuggestions(logger *log.Logger, name string, apps []*argocdv1alpha1.Application, appsets []*argocdv1alpha1.ApplicationSet) {
+	names := []string{}
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	for _, appset := range appsets {
+		names = append(names, appset.Name)
+	}
+	if suggestions := suggest.Closest(name, names...); len(suggestions) > 0 {
+		logger.Infof("🤔 did you mean: %s", strings.Join(suggestions, ", "))
+	} else {
+		logger.Info("🤨 no similar Application or ApplicationSet")
+	}
+}
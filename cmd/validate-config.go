@@ -1,12 +1,22 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/deprecations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/gitrepo"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
 	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
 
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	charmlog "github.com/charmbracelet/log"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
@@ -14,12 +24,24 @@ import (
 
 func NewValidateConfigCmd() *cobra.Command {
 
-	var apps, components []string
+	var apps, components, projects []string
 	var baseDir string
+	var clusterInventory string
+	var expectedSources string
+	var namespaces bool
+	var secrets bool
+	var allowedSecrets []string
+	var imagePolicy bool
+	var allowedRegistries []string
+	var imageReport string
+	var kubeVersion string
+	var since string
+	var watch bool
+	var fix bool
 	var verbose bool
 
 	checkCmd := &cobra.Command{
-		Use:   "check-config --base-dir=$(pwd) --apps apps-of-apps,apps --components components --verbose=false",
+		Use:   "check-config --base-dir=$(pwd) --apps apps-of-apps,apps --components components [--projects projects] [--clusters clusters] [--expected-sources expected-sources.yaml] [--namespaces] [--secrets [--allowed-secrets patterns]] [--image-policy [--allowed-registries registries]] [--image-report images.json] [--kube-version 1.32] [--since=<git-ref>] [--fix] [--watch] --verbose=false",
 		Short: "Checks the Argo CD configuration",
 		Args:  cobra.ExactArgs(0),
 
@@ -33,16 +55,130 @@ func NewValidateConfigCmd() *cobra.Command {
 			afs := afero.Afero{
 				Fs: afero.NewOsFs(),
 			}
+			options := []validation.Option{}
+			if since != "" {
+				// only check the kustomizations and Applications affected by the changes since the given revision
+				files, err := gitrepo.ChangedFiles(logger, baseDir, since)
+				if err != nil {
+					logger.Error("failed to list changed files", "since", since, "err", err)
+					os.Exit(1)
+				}
+				graph, err := kustomizations.NewGraph(logger, afs, baseDir, append(apps, components...)...)
+				if err != nil {
+					logger.Error(strings.ReplaceAll(err.Error(), ": ", ":\n"))
+					os.Exit(1)
+				}
+				affected := graph.Affected(files...)
+				logger.Info("🔎 checking changes only", "since", since, "files", len(files), "kustomizations", len(affected))
+				options = append(options, validation.WithSelection(append(affected, files...)...))
+			}
+			// verify the Applications against the constraints of their AppProject,
+			// found in the given paths or else anywhere in the repository
+			projectPaths := projects
+			if len(projectPaths) == 0 {
+				projectPaths = []string{"."}
+			}
+			appProjects := []*argocdv1alpha1.AppProject{}
+			for _, p := range projectPaths {
+				ps, err := applications.ListAppProjects(logger, afs, filepath.Join(baseDir, p))
+				if err != nil {
+					logger.Error("failed to list AppProjects", "path", p, "err", err)
+					os.Exit(1)
+				}
+				appProjects = append(appProjects, ps...)
+			}
+			switch {
+			case len(projects) > 0:
+				options = append(options, validation.WithProjects(appProjects...))
+			case len(appProjects) > 0:
+				// Applications whose project only exists in the cluster are not checked
+				options = append(options, validation.WithRepositoryProjects(appProjects...))
+			}
+			if clusterInventory != "" {
+				// verify that the Application destinations resolve to a known cluster
+				inventory, err := clusters.Load(logger, afs, filepath.Join(baseDir, clusterInventory))
+				if err != nil {
+					logger.Error("failed to load cluster inventory", "path", clusterInventory, "err", err)
+					os.Exit(1)
+				}
+				options = append(options, validation.WithClusters(inventory))
+			}
+			if expectedSources != "" {
+				// verify that the Applications use the expected repoURL and targetRevision
+				expectations, err := validation.ReadSourceExpectations(afs, filepath.Join(baseDir, expectedSources))
+				if err != nil {
+					logger.Error("failed to read source expectations", "path", expectedSources, "err", err)
+					os.Exit(1)
+				}
+				options = append(options, validation.WithSourceExpectations(expectations...))
+			}
+			if namespaces {
+				// verify the namespaces of the objects rendered by the Applications
+				options = append(options, validation.WithNamespaces())
+			}
+			if secrets {
+				// verify that there is no sensitive data in the files and in the rendered objects
+				options = append(options, validation.WithSecrets(allowedSecrets...))
+			}
+			if imagePolicy {
+				// verify the images of the workloads rendered by the Applications
+				options = append(options, validation.WithImagePolicy(images.Policy{
+					AllowedRegistries: allowedRegistries,
+				}))
+			}
+			inventory := []images.Inventory{}
+			if imageReport != "" {
+				options = append(options, validation.WithImageInventory(&inventory))
+			}
+			if kubeVersion != "" {
+				// verify that the rendered objects do not use an API version which is removed in the target Kubernetes version
+				version, err := deprecations.ParseVersion(kubeVersion)
+				if err != nil {
+					logger.Error(err.Error())
+					os.Exit(1)
+				}
+				options = append(options, validation.WithKubeVersion(version))
+			}
+			fixes := []validation.Fix{}
+			if fix {
+				// add the unreferenced resources and remove the references to missing resources in the kustomization files
+				options = append(options, validation.WithFix(&fixes))
+			}
+			checker := validation.NewChecker(logger, afs, baseDir, options...)
+			if watch {
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				if err := checker.Watch(ctx, apps, components); err != nil {
+					logger.Error(strings.ReplaceAll(err.Error(), ": ", ":\n"))
+					os.Exit(1)
+				}
+				return
+			}
 			// verifies that the source path of the Applications and ApplicationSets exists
-			if err := validation.CheckApplications(logger, afs, baseDir, apps...); err != nil {
+			if err := checker.CheckApplications(apps...); err != nil {
+				logFixes(logger, fix, fixes)
 				logger.Error(strings.ReplaceAll(err.Error(), ": ", ":\n"))
 				os.Exit(1)
 			}
 			// verifies that `kustomize build` on each component completes successfully
-			if err := validation.CheckComponents(logger, afs, baseDir, components...); err != nil {
+			if err := checker.CheckComponents(components...); err != nil {
+				logFixes(logger, fix, fixes)
 				logger.Error(strings.ReplaceAll(err.Error(), ": ", ":\n"))
 				os.Exit(1)
 			}
+			logFixes(logger, fix, fixes)
+			// verifies that the Applications and ApplicationSets use the expected repoURL and targetRevision
+			if err := checker.CheckSources(); err != nil {
+				logger.Error(strings.ReplaceAll(err.Error(), ": ", ":\n"))
+				os.Exit(1)
+			}
+			if imageReport != "" {
+				if err := writeImageReport(afs, imageReport, inventory); err != nil {
+					logger.Error("failed to write the image report", "path", imageReport, "err", err)
+					os.Exit(1)
+				}
+				logger.Info("📝 wrote image report", "path", imageReport, "applications", len(inventory))
+			}
 		},
 	}
 
@@ -55,7 +191,47 @@ func NewValidateConfigCmd() *cobra.Command {
 	if err := checkCmd.MarkFlagRequired("components"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %s", err))
 	}
+	checkCmd.Flags().StringSliceVar(&projects, "projects", []string{}, "path(s) to the AppProjects used to verify the Applications (comma-separated, relative to '--baseDir'). By default, the AppProjects are looked for in the whole repository")
+	checkCmd.Flags().StringVar(&clusterInventory, "clusters", "", "path to the cluster inventory (Argo CD cluster Secrets or list of clusters) used to verify the Application destinations (relative to '--baseDir')")
+	checkCmd.Flags().StringVar(&expectedSources, "expected-sources", "", "path to the file with the expected repoURL and targetRevision of the Applications per directory (relative to '--baseDir')")
+	checkCmd.Flags().BoolVar(&namespaces, "namespaces", false, "render the Applications and verify the namespaces of the objects against the destination namespace")
+	checkCmd.Flags().BoolVar(&secrets, "secrets", false, "verify that the files and the rendered objects contain no Secret with data and no high-entropy value in ConfigMaps")
+	checkCmd.Flags().StringSliceVar(&allowedSecrets, "allowed-secrets", []string{}, "pattern(s) of the paths in which Secrets are allowed (comma-separated, relative to '--baseDir')")
+	checkCmd.Flags().BoolVar(&imagePolicy, "image-policy", false, "verify that the images of the Applications are pinned by digest or use a semver tag (not 'latest') and come from an allowed registry")
+	checkCmd.Flags().StringSliceVar(&allowedRegistries, "allowed-registries", []string{}, "registries (or registry/organization prefixes) allowed by the image policy (comma-separated, all registries are allowed if empty)")
+	checkCmd.Flags().StringVar(&imageReport, "image-report", "", "path to the JSON file in which the images used by each Application are written ('-' for stdout)")
+	checkCmd.Flags().StringVar(&kubeVersion, "kube-version", "", "target Kubernetes version (eg: '1.32') in which the API versions of the rendered objects must not be removed")
+	checkCmd.Flags().StringVar(&since, "since", "", "only check the kustomizations and Applications affected by the changes since the given Git revision (from its merge-base with HEAD)")
+	checkCmd.Flags().BoolVar(&fix, "fix", false, "add the unreferenced files to the 'resources' of the kustomization files and remove the entries referring to missing files")
+	checkCmd.Flags().BoolVar(&watch, "watch", false, "watch the base directory and check the kustomizations and Applications affected by each change")
 	checkCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	return checkCmd
 
 }
+
+// prints a summary of the edits made in fix mode
+func logFixes(logger *charmlog.Logger, fix bool, fixes []validation.Fix) {
+	if !fix {
+		return
+	}
+	if len(fixes) == 0 {
+		logger.Info("🔧 nothing to fix")
+		return
+	}
+	for _, f := range fixes {
+		logger.Info("🔧 fixed resources", "path", f.Path, "added", strings.Join(f.Added, ","), "removed", strings.Join(f.Removed, ","))
+	}
+}
+
+// writes the image inventory in JSON in the given file, or on stdout
+func writeImageReport(afs afero.Afero, path string, inventory []images.Inventory) error {
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err := fmt.Fprintln(os.Stdout, string(data))
+		return err
+	}
+	return afs.WriteFile(path, data, 0600)
+}
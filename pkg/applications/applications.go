@@ -1,48 +1,123 @@
 package applications
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	fs "io/fs"
 	"path/filepath"
+	"strings"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/types"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func ListApplications(logger *log.Logger, afs afero.Afero, baseDir string) ([]*argocdv1alpha1.Application, []*argocdv1alpha1.ApplicationSet, error) {
 	logger.Info("👀 looking for Applications", "path", baseDir)
 	apps := []*argocdv1alpha1.Application{}
 	appsets := []*argocdv1alpha1.ApplicationSet{}
-	err := afs.Walk(baseDir, func(path string, info fs.FileInfo, err error) error {
+	err := WalkApplications(logger, afs, baseDir, func(_ string, app *argocdv1alpha1.Application, appset *argocdv1alpha1.ApplicationSet) {
+		if app != nil {
+			apps = append(apps, app)
+		}
+		if appset != nil {
+			appsets = append(appsets, appset)
+		}
+	})
+	return apps, appsets, err
+}
+
+// ListApplicationFiles returns the paths of the files in which `ListApplications` finds an Application or an ApplicationSet
+func ListApplicationFiles(logger *log.Logger, afs afero.Afero, baseDir string) ([]string, error) {
+	files := []string{}
+	err := WalkApplications(logger, afs, baseDir, func(path string, _ *argocdv1alpha1.Application, _ *argocdv1alpha1.ApplicationSet) {
+		// the documents of a file are visited one after the other
+		if len(files) == 0 || files[len(files)-1] != path {
+			files = append(files, path)
+		}
+	})
+	return files, err
+}
+
+// WalkApplications walks the given directory and calls the given function with each Argo CD Application or ApplicationSet
+// found in the YAML files, along with the path of the file (see `walkDocuments`), regardless of their destination.
+func WalkApplications(logger *log.Logger, afs afero.Afero, baseDir string, fn func(path string, app *argocdv1alpha1.Application, appset *argocdv1alpha1.ApplicationSet)) error {
+	return walkDocuments(logger, afs, baseDir, func(path string, n *kyaml.RNode) {
+		switch n.GetKind() {
+		case "Application":
+			app := &argocdv1alpha1.Application{}
+			if err := unmarshal(n, app); err != nil {
+				logger.Debug("skipping invalid Application", "path", path, "error", err)
+				return
+			}
+			fn(path, app, nil)
+		case "ApplicationSet":
+			appset := &argocdv1alpha1.ApplicationSet{}
+			if err := unmarshal(n, appset); err != nil {
+				logger.Debug("skipping invalid ApplicationSet", "path", path, "error", err)
+				return
+			}
+			fn(path, nil, appset)
+		}
+	})
+}
+
+// walks the given directory and calls the given function with each document of the YAML files (including all the
+// documents of multi-document files) whose `apiVersion` belongs to the Argo CD API group.
+// Hidden directories (eg: `.git`) and invalid YAML files are skipped.
+func walkDocuments(logger *log.Logger, afs afero.Afero, baseDir string, fn func(path string, node *kyaml.RNode)) error {
+	return afs.Walk(baseDir, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			logger.Error("prevent panic by handling failure", "path", path)
 			return err
 		}
 		if info.IsDir() {
+			if path != baseDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(info.Name()) != ".yaml" {
 			return nil
 		}
 		data, err := afs.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		if filepath.Ext(info.Name()) == ".yaml" {
-			logger.Debug("checking contents", "path", path)
-			app := &argocdv1alpha1.Application{}
-			if err := yaml.Unmarshal(data, app); err == nil && app.Spec.Destination.Server != "" {
-				apps = append(apps, app)
-			}
-			appset := &argocdv1alpha1.ApplicationSet{}
-			if err := yaml.Unmarshal(data, appset); err == nil && appset.Spec.Template.Spec.Destination.Server != "" {
-				appsets = append(appsets, appset)
+		logger.Debug("checking contents", "path", path)
+		nodes, err := (&kio.ByteReader{
+			Reader:                bytes.NewReader(data),
+			OmitReaderAnnotations: true,
+		}).Read()
+		if err != nil {
+			logger.Debug("skipping invalid YAML file", "path", path, "error", err)
+			return nil
+		}
+		for _, n := range nodes {
+			if IsArgoCD(n.GetApiVersion()) {
+				fn(path, n)
 			}
 		}
 		return nil
 	})
-	return apps, appsets, err
+}
+
+// IsArgoCD returns true if the given `apiVersion` belongs to the Argo CD API group
+func IsArgoCD(apiVersion string) bool {
+	return strings.HasPrefix(apiVersion, argocdv1alpha1.SchemeGroupVersion.Group+"/")
+}
+
+func unmarshal(node *kyaml.RNode, obj interface{}) error {
+	data, err := node.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, obj)
 }
 
 func CreateApplication(ctx context.Context, logger *log.Logger, cl runtimeclient.Client, app *argocdv1alpha1.Application) error {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/charmbracelet/log"
@@ -60,6 +61,75 @@ func TestListApplications(t *testing.T) {
 		require.Len(t, appsets, 1)
 		assert.Equal(t, "appset-pasta", appsets[0].Name)
 	})
+
+	t.Run("apps by destination name and multiple documents", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/apps.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-pasta
+spec:
+  destination:
+    name: member-1
+  project: default
+  source:
+    path: components/pasta
+---
+apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-pizza
+spec:
+  template:
+    spec:
+      destination:
+        name: "{{cluster}}"
+      project: default
+      source:
+        path: components/pizza
+---
+# not an Argo CD Application
+apiVersion: app.k8s.io/v1beta1
+kind: Application
+metadata:
+  name: cake
+spec:
+  descriptor:
+    type: cake`,
+		})
+		logger := log.New(os.Stdout)
+
+		// when
+		apps, appsets, err := applications.ListApplications(logger, afs, baseDir)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, apps, 2)
+		assert.Equal(t, "app-cookie", apps[0].Name)
+		assert.Equal(t, "app-pasta", apps[1].Name)
+		assert.Equal(t, "member-1", apps[1].Spec.Destination.Name)
+		require.Len(t, appsets, 1)
+		assert.Equal(t, "appset-pizza", appsets[0].Name)
+
+		// when
+		files, err := applications.ListApplicationFiles(logger, afs, baseDir)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/path/to/apps/apps.yaml"}, files)
+	})
 }
 
 var appCookieData = []byte(`
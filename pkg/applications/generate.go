@@ -0,0 +1,106 @@
+package applications
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var placeholder = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// GenerateApplications returns the Applications that the given ApplicationSet would generate.
+// Only the elements of the `list` generators are substituted in the template (`{{key}}`, or `{{ .key }}` with `goTemplate`)
+// since the other generators need to access the cluster or remote services. For these other generators,
+// the template is returned as-is, with its placeholders.
+func GenerateApplications(appset *argocdv1alpha1.ApplicationSet) ([]*argocdv1alpha1.Application, error) {
+	apps := []*argocdv1alpha1.Application{}
+	for _, g := range appset.Spec.Generators {
+		if g.List == nil {
+			app, err := newApplication(appset.Spec.Template, nil)
+			if err != nil {
+				return nil, err
+			}
+			apps = append(apps, app)
+			continue
+		}
+		for _, e := range g.List.Elements {
+			params := map[string]interface{}{}
+			if err := json.Unmarshal(e.Raw, &params); err != nil {
+				return nil, fmt.Errorf("invalid element in list generator of ApplicationSet '%s': %w", appset.Name, err)
+			}
+			app, err := newApplication(appset.Spec.Template, flatten("", params))
+			if err != nil {
+				return nil, err
+			}
+			apps = append(apps, app)
+		}
+	}
+	return apps, nil
+}
+
+// returns a new Application from the given template, in which the `{{key}}` placeholders are replaced by the
+// corresponding value (when it exists)
+func newApplication(tmpl argocdv1alpha1.ApplicationSetTemplate, params map[string]string) (*argocdv1alpha1.Application, error) {
+	data, err := json.Marshal(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	data = placeholder.ReplaceAllFunc(data, func(m []byte) []byte {
+		key := strings.TrimPrefix(string(placeholder.FindSubmatch(m)[1]), ".")
+		value, found := params[key]
+		if !found {
+			return m
+		}
+		// value is inserted in a JSON string
+		escaped, _ := json.Marshal(value)
+		return escaped[1 : len(escaped)-1]
+	})
+	result := argocdv1alpha1.ApplicationSetTemplate{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &argocdv1alpha1.Application{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "argoproj.io/v1alpha1",
+			Kind:       "Application",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        result.Name,
+			Namespace:   result.Namespace,
+			Labels:      result.Labels,
+			Annotations: result.Annotations,
+			Finalizers:  result.Finalizers,
+		},
+		Spec: result.Spec,
+	}, nil
+}
+
+// flattens the nested params with a dot-separated key (eg: `values.cluster`)
+func flatten(prefix string, params map[string]interface{}) map[string]string {
+	result := map[string]string{}
+	for k, v := range params {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for fk, fv := range flatten(k, v) {
+				result[fk] = fv
+			}
+		case string:
+			result[k] = v
+		default:
+			result[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return result
+}
+
+// IsTemplated returns true if the given value contains a `{{...}}` placeholder
+func IsTemplated(value string) bool {
+	return placeholder.MatchString(value)
+}
@@ -0,0 +1,174 @@
+package applications_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGenerateApplications(t *testing.T) {
+
+	t.Run("list generator", func(t *testing.T) {
+		// given
+		appset := newApplicationSet(t, `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-pasta
+spec:
+  generators:
+  - list:
+      elements:
+      - cluster: member-1
+        values:
+          namespace: pasta
+      - cluster: member-2
+        values:
+          namespace: spaghetti
+  template:
+    metadata:
+      name: 'pasta-{{cluster}}'
+    spec:
+      destination:
+        name: '{{cluster}}'
+        namespace: '{{values.namespace}}'
+      project: default
+      source:
+        path: components/pasta`)
+
+		// when
+		apps, err := applications.GenerateApplications(appset)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, apps, 2)
+		assert.Equal(t, "pasta-member-1", apps[0].Name)
+		assert.Equal(t, "member-1", apps[0].Spec.Destination.Name)
+		assert.Equal(t, "pasta", apps[0].Spec.Destination.Namespace)
+		assert.Equal(t, "pasta-member-2", apps[1].Name)
+		assert.Equal(t, "member-2", apps[1].Spec.Destination.Name)
+		assert.Equal(t, "spaghetti", apps[1].Spec.Destination.Namespace)
+	})
+
+	t.Run("list generator with go template", func(t *testing.T) {
+		// given
+		appset := newApplicationSet(t, `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-pasta
+spec:
+  goTemplate: true
+  generators:
+  - list:
+      elements:
+      - cluster: member-1
+  template:
+    metadata:
+      name: 'pasta-{{ .cluster }}'
+    spec:
+      destination:
+        name: '{{ .cluster }}'
+      project: default
+      source:
+        path: components/pasta`)
+
+		// when
+		apps, err := applications.GenerateApplications(appset)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		assert.Equal(t, "pasta-member-1", apps[0].Name)
+		assert.Equal(t, "member-1", apps[0].Spec.Destination.Name)
+	})
+
+	t.Run("other generator", func(t *testing.T) {
+		// given
+		appset := newApplicationSet(t, `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-pasta
+spec:
+  generators:
+  - clusters: {}
+  template:
+    metadata:
+      name: 'pasta-{{name}}'
+    spec:
+      destination:
+        server: '{{server}}'
+      project: default
+      source:
+        path: components/pasta`)
+
+		// when
+		apps, err := applications.GenerateApplications(appset)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, apps, 1)
+		assert.Equal(t, "pasta-{{name}}", apps[0].Name)
+		assert.True(t, applications.IsTemplated(apps[0].Spec.Destination.Server))
+	})
+}
+
+func TestListAppProjects(t *testing.T) {
+
+	// given
+	baseDir := "/path/to/projects"
+	afs, err := newAfs(baseDir)
+	require.NoError(t, err)
+	err = afs.WriteFile(filepath.Join(baseDir, "sandbox.yaml"), []byte(`apiVersion: argoproj.io/v1alpha1
+kind: AppProject
+metadata:
+  name: sandbox
+spec:
+  sourceRepos:
+  - '*'`), 0755)
+	require.NoError(t, err)
+	err = afs.WriteFile(filepath.Join(baseDir, "app-cookie.yaml"), appCookieData, 0755)
+	require.NoError(t, err)
+	// multiple documents
+	err = afs.WriteFile(filepath.Join(baseDir, "tenants/projects.yaml"), []byte(`apiVersion: argoproj.io/v1alpha1
+kind: AppProject
+metadata:
+  name: tenant-1
+---
+apiVersion: argoproj.io/v1alpha1
+kind: AppProject
+metadata:
+  name: tenant-2`), 0755)
+	require.NoError(t, err)
+	// hidden directory
+	err = afs.WriteFile(filepath.Join(baseDir, ".cache/sandbox.yaml"), []byte(`apiVersion: argoproj.io/v1alpha1
+kind: AppProject
+metadata:
+  name: cached`), 0755)
+	require.NoError(t, err)
+	logger := log.New(os.Stdout)
+
+	// when
+	projects, err := applications.ListAppProjects(logger, afs, baseDir)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, projects, 3)
+	assert.Equal(t, "sandbox", projects[0].Name)
+	assert.Equal(t, []string{"*"}, projects[0].Spec.SourceRepos)
+	assert.Equal(t, "tenant-1", projects[1].Name)
+	assert.Equal(t, "tenant-2", projects[2].Name)
+}
+
+func newApplicationSet(t *testing.T, data string) *argocdv1alpha1.ApplicationSet {
+	appset := &argocdv1alpha1.ApplicationSet{}
+	err := yaml.Unmarshal([]byte(data), appset)
+	require.NoError(t, err)
+	return appset
+}
@@ -0,0 +1,27 @@
+package applications
+
+import (
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ListAppProjects returns the Argo CD AppProjects found in the YAML files of the given directory and its subdirectories
+// (see `walkDocuments`)
+func ListAppProjects(logger *log.Logger, afs afero.Afero, baseDir string) ([]*argocdv1alpha1.AppProject, error) {
+	logger.Info("👀 looking for AppProjects", "path", baseDir)
+	projects := []*argocdv1alpha1.AppProject{}
+	err := walkDocuments(logger, afs, baseDir, func(path string, n *kyaml.RNode) {
+		if n.GetKind() != "AppProject" {
+			return
+		}
+		project := &argocdv1alpha1.AppProject{}
+		if err := unmarshal(n, project); err != nil {
+			logger.Debug("skipping invalid AppProject", "path", path, "error", err)
+			return
+		}
+		projects = append(projects, project)
+	})
+	return projects, err
+}
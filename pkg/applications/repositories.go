@@ -0,0 +1,294 @@
+package applications
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// RepoRewrite a rewrite of the repository URL of the sources which refer to a given repository (eg: the upstream repository),
+// to point to another repository (eg: a fork or a mirror), optionally with another target revision
+type RepoRewrite struct {
+	// From URL of the repository to rewrite
+	From string `json:"from"`
+	// To URL of the repository to use instead
+	To string `json:"to"`
+	// TargetRevision the target revision to use in the rewritten sources (unchanged if empty)
+	TargetRevision string `json:"targetRevision,omitempty"`
+}
+
+// RepoRewrites a set of repository rewrites
+type RepoRewrites []RepoRewrite
+
+// ParseRepoRewrite parses a repository rewrite in the `<from>=<to>[@<revision>]` format
+// (eg: `https://github.com/codeready-toolchain/sandbox-argocd=https://github.com/me/sandbox-argocd@my-branch`)
+func ParseRepoRewrite(s string) (RepoRewrite, error) {
+	from, to, found := strings.Cut(s, "=")
+	if !found || from == "" || to == "" {
+		return RepoRewrite{}, fmt.Errorf("invalid repository rewrite: '%s' (expected '<from>=<to>[@<revision>]')", s)
+	}
+	r := RepoRewrite{
+		From: from,
+		To:   to,
+	}
+	// the `@` in the user info of the URL (eg: `git@github.com:me/repo.git`) is not a revision separator
+	if i := strings.LastIndex(to, "@"); i > pathStart(to) {
+		r.To, r.TargetRevision = to[:i], to[i+1:]
+	}
+	if r.To == "" {
+		return RepoRewrite{}, fmt.Errorf("invalid repository rewrite: '%s' (expected '<from>=<to>[@<revision>]')", s)
+	}
+	return r, nil
+}
+
+// LoadRepoRewrites reads the repository rewrites in the given YAML file, which contains a list of rewrites
+// (eg: `[{from: https://github.com/org/repo, to: https://github.com/me/repo, targetRevision: my-branch}]`)
+func LoadRepoRewrites(afs afero.Afero, path string) (RepoRewrites, error) {
+	data, err := afs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rewrites := RepoRewrites{}
+	if err := yaml.UnmarshalStrict(data, &rewrites); err != nil {
+		return nil, fmt.Errorf("invalid repository rewrites in %s: %w", path, err)
+	}
+	for i, r := range rewrites {
+		if r.From == "" || r.To == "" {
+			return nil, fmt.Errorf("invalid repository rewrite #%d in %s: 'from' and 'to' are required", i, path)
+		}
+	}
+	return rewrites, nil
+}
+
+// lookup returns the rewrite of the given repository URL, if any
+func (r RepoRewrites) lookup(repoURL string) (RepoRewrite, bool) {
+	for _, rw := range r {
+		if normalizeRepoURL(rw.From) == normalizeRepoURL(repoURL) {
+			return rw, true
+		}
+	}
+	return RepoRewrite{}, false
+}
+
+// Application rewrites the sources of the given Application (in memory). Sources which refer to other repositories are unchanged.
+// Returns the number of rewritten sources.
+func (r RepoRewrites) Application(app *argocdv1alpha1.Application) int {
+	return r.rewriteSpec(&app.Spec)
+}
+
+// ApplicationSet rewrites the sources of the template of the given ApplicationSet, and the Git generators (in memory),
+// including the Git generators nested in `matrix` and `merge` generators.
+// Sources and generators which refer to other repositories are unchanged.
+// Returns the number of rewritten sources and generators.
+func (r RepoRewrites) ApplicationSet(appset *argocdv1alpha1.ApplicationSet) int {
+	count := r.rewriteSpec(&appset.Spec.Template.Spec)
+	for _, g := range appset.Spec.Generators {
+		count += r.rewriteGit(g.Git)
+		if g.Matrix != nil {
+			count += r.rewriteNestedGenerators(g.Matrix.Generators)
+		}
+		if g.Merge != nil {
+			count += r.rewriteNestedGenerators(g.Merge.Generators)
+		}
+	}
+	return count
+}
+
+// rewrites the Git generators of a `matrix` or `merge` generator, including the ones of the `matrix` and `merge` generators
+// at the second level, which are kept as raw JSON in the ApplicationSet (and ignored if invalid)
+func (r RepoRewrites) rewriteNestedGenerators(generators []argocdv1alpha1.ApplicationSetNestedGenerator) int {
+	count := 0
+	for _, g := range generators {
+		count += r.rewriteGit(g.Git)
+		if nested, err := argocdv1alpha1.ToNestedMatrixGenerator(g.Matrix); err == nil && nested != nil {
+			count += r.rewriteTerminalGenerators(g.Matrix, nested, nested.Generators)
+		}
+		if nested, err := argocdv1alpha1.ToNestedMergeGenerator(g.Merge); err == nil && nested != nil {
+			count += r.rewriteTerminalGenerators(g.Merge, nested, nested.Generators)
+		}
+	}
+	return count
+}
+
+// rewrites the Git generators of the given (decoded) `matrix` or `merge` generator, and encodes it back in the raw JSON
+func (r RepoRewrites) rewriteTerminalGenerators(raw *apiextensionsv1.JSON, nested any, generators argocdv1alpha1.ApplicationSetTerminalGenerators) int {
+	count := 0
+	for _, g := range generators {
+		count += r.rewriteGit(g.Git)
+	}
+	if count == 0 {
+		return 0
+	}
+	data, err := json.Marshal(nested)
+	if err != nil {
+		return 0
+	}
+	raw.Raw = data
+	return count
+}
+
+// rewrites the repository URL and revision of the given Git generator, if any.
+// Returns 1 if the generator was rewritten, 0 otherwise.
+func (r RepoRewrites) rewriteGit(git *argocdv1alpha1.GitGenerator) int {
+	if git == nil {
+		return 0
+	}
+	rw, found := r.lookup(git.RepoURL)
+	if !found {
+		return 0
+	}
+	git.RepoURL = rw.To
+	if rw.TargetRevision != "" {
+		git.Revision = rw.TargetRevision
+	}
+	return 1
+}
+
+func (r RepoRewrites) rewriteSpec(spec *argocdv1alpha1.ApplicationSpec) int {
+	count := 0
+	rewrite := func(source *argocdv1alpha1.ApplicationSource) {
+		if rw, found := r.lookup(source.RepoURL); found {
+			source.RepoURL = rw.To
+			if rw.TargetRevision != "" {
+				source.TargetRevision = rw.TargetRevision
+			}
+			count++
+		}
+	}
+	if spec.Source != nil {
+		rewrite(spec.Source)
+	}
+	for i := range spec.Sources {
+		rewrite(&spec.Sources[i])
+	}
+	return count
+}
+
+// OverrideRepository sets the given repository URL and target revision on all the sources (`spec.source` and `spec.sources`)
+// of the given Application spec (in memory), except the Helm chart sources, whose repository is a Helm repository.
+// Returns the number of overridden sources.
+func OverrideRepository(spec *argocdv1alpha1.ApplicationSpec, repoURL, targetRevision string) int {
+	count := 0
+	override := func(source *argocdv1alpha1.ApplicationSource) {
+		if source.Chart != "" {
+			return
+		}
+		source.RepoURL = repoURL
+		source.TargetRevision = targetRevision
+		count++
+	}
+	if spec.Source != nil {
+		override(spec.Source)
+	}
+	for i := range spec.Sources {
+		override(&spec.Sources[i])
+	}
+	return count
+}
+
+// RewriteRepositories applies the given repository rewrites on the sources (`spec.source` and `spec.sources`) of the Applications,
+// and on the templates and the Git generators (including the ones nested in `matrix` and `merge` generators)
+// of the ApplicationSets found in the given directory (see `ListApplicationFiles`).
+// The files are edited in place (comments are preserved), only when `write` is true.
+// Returns the changes (or the changes which would be made), in the order of the files and of the objects in each file.
+func RewriteRepositories(logger *log.Logger, afs afero.Afero, baseDir string, rewrites RepoRewrites, write bool) ([]SourceChange, error) {
+	return editSources(logger, afs, baseDir, write, func(node *kyaml.RNode) ([]string, error) {
+		fields := []string{}
+		rewrite := func(source *kyaml.RNode, path, revisionField string) error {
+			repoURL := source.Field("repoURL")
+			if repoURL == nil {
+				return nil
+			}
+			rw, found := rewrites.lookup(repoURL.Value.YNode().Value)
+			if !found {
+				return nil
+			}
+			if setField(source, "repoURL", repoURL.Value.YNode().Value, rw.To) {
+				fields = append(fields, path+".repoURL")
+			}
+			if rw.TargetRevision == "" {
+				return nil
+			}
+			if revision := source.Field(revisionField); revision != nil {
+				if setField(source, revisionField, revision.Value.YNode().Value, rw.TargetRevision) {
+					fields = append(fields, path+"."+revisionField)
+				}
+				return nil
+			}
+			// the revision is `HEAD` by default
+			fields = append(fields, path+"."+revisionField)
+			return source.PipeE(kyaml.SetField(revisionField, kyaml.NewStringRNode(rw.TargetRevision)))
+		}
+		if err := visitSources(node, func(source *kyaml.RNode, path string) error {
+			return rewrite(source, path, "targetRevision")
+		}); err != nil {
+			return nil, err
+		}
+		if node.GetKind() != "ApplicationSet" {
+			return fields, nil
+		}
+		var rewriteGenerators func(generators *kyaml.RNode, path string) error
+		rewriteGenerators = func(generators *kyaml.RNode, path string) error {
+			elements, err := generators.Elements()
+			if err != nil {
+				return err
+			}
+			for i, g := range elements {
+				gpath := fmt.Sprintf("%s[%d]", path, i)
+				if git := g.Field("git"); git != nil {
+					if err := rewrite(git.Value, gpath+".git", "revision"); err != nil {
+						return err
+					}
+				}
+				// generators nested in `matrix` and `merge` generators
+				for _, combination := range []string{"matrix", "merge"} {
+					nested, err := g.Pipe(kyaml.Lookup(combination, "generators"))
+					if err != nil {
+						return err
+					}
+					if nested != nil {
+						if err := rewriteGenerators(nested, gpath+"."+combination+".generators"); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return nil
+		}
+		generators, err := node.Pipe(kyaml.Lookup("spec", "generators"))
+		if err != nil || generators == nil {
+			return fields, err
+		}
+		if err := rewriteGenerators(generators, "spec.generators"); err != nil {
+			return nil, err
+		}
+		return fields, nil
+	})
+}
+
+// normalizes the given repository URL, so that the equivalent URLs of a repository match
+// (eg: `https://github.com/org/repo.git` and `https://github.com/org/repo`)
+func normalizeRepoURL(repoURL string) string {
+	repoURL = strings.ToLower(strings.TrimSpace(repoURL))
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	return strings.TrimSuffix(repoURL, ".git")
+}
+
+// returns the index at which the path of the given repository URL starts (ie, after the scheme, the user info and the host)
+func pathStart(repoURL string) int {
+	if i := strings.Index(repoURL, "://"); i >= 0 {
+		if j := strings.Index(repoURL[i+3:], "/"); j >= 0 {
+			return i + 3 + j
+		}
+		return len(repoURL)
+	}
+	// SCP-like syntax (eg: `git@github.com:org/repo.git`)
+	return strings.Index(repoURL, ":")
+}
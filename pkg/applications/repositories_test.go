@@ -0,0 +1,505 @@
+package applications_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	upstream   = "https://github.com/codeready-toolchain/sandbox-argocd"
+	fork       = "https://github.com/me/sandbox-argocd"
+	thirdParty = "https://github.com/argoproj/argo-cd"
+)
+
+func TestParseRepoRewrite(t *testing.T) {
+
+	for value, expected := range map[string]applications.RepoRewrite{
+		upstream + "=" + fork:                        {From: upstream, To: fork},
+		upstream + "=" + fork + "@my-branch":         {From: upstream, To: fork, TargetRevision: "my-branch"},
+		upstream + "=" + fork + "@feature/cookie":    {From: upstream, To: fork, TargetRevision: "feature/cookie"},
+		upstream + "=git@github.com:me/repo.git":     {From: upstream, To: "git@github.com:me/repo.git"},
+		upstream + "=git@github.com:me/repo.git@dev": {From: upstream, To: "git@github.com:me/repo.git", TargetRevision: "dev"},
+		upstream + "=https://me@example.com/repo":    {From: upstream, To: "https://me@example.com/repo"},
+	} {
+		t.Run(value, func(t *testing.T) {
+			// when
+			rw, err := applications.ParseRepoRewrite(value)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, expected, rw)
+		})
+	}
+
+	for _, value := range []string{"", upstream, upstream + "=", "=" + fork, upstream + "=@my-branch"} {
+		t.Run("invalid: "+value, func(t *testing.T) {
+			// when
+			_, err := applications.ParseRepoRewrite(value)
+
+			// then
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadRepoRewrites(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/rewrites.yaml", []byte(`- from: `+upstream+`
+  to: `+fork+`
+  targetRevision: my-branch
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		rewrites, err := applications.LoadRepoRewrites(afs, "/path/to/rewrites.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, applications.RepoRewrites{
+			{From: upstream, To: fork, TargetRevision: "my-branch"},
+		}, rewrites)
+	})
+
+	t.Run("missing target", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/rewrites.yaml", []byte(`- from: `+upstream+`
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		_, err = applications.LoadRepoRewrites(afs, "/path/to/rewrites.yaml")
+
+		// then
+		require.EqualError(t, err, "invalid repository rewrite #0 in /path/to/rewrites.yaml: 'from' and 'to' are required")
+	})
+}
+
+func TestRepoRewrites(t *testing.T) {
+
+	rewrites := applications.RepoRewrites{
+		{From: upstream, To: fork, TargetRevision: "my-branch"},
+	}
+
+	t.Run("multi-source Application", func(t *testing.T) {
+		// given
+		app := &argocdv1alpha1.Application{}
+		err := yaml.Unmarshal([]byte(`apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  sources:
+  - repoURL: `+upstream+`.git
+    path: components/cookie
+    targetRevision: master
+  - repoURL: `+thirdParty+`
+    path: manifests
+    targetRevision: v2.12.4
+`), app)
+		require.NoError(t, err)
+
+		// when
+		count := rewrites.Application(app)
+
+		// then
+		assert.Equal(t, 1, count)
+		assert.Equal(t, fork, app.Spec.Sources[0].RepoURL)
+		assert.Equal(t, "my-branch", app.Spec.Sources[0].TargetRevision)
+		assert.Equal(t, thirdParty, app.Spec.Sources[1].RepoURL)
+		assert.Equal(t, "v2.12.4", app.Spec.Sources[1].TargetRevision)
+	})
+
+	t.Run("ApplicationSet", func(t *testing.T) {
+		// given
+		appset := &argocdv1alpha1.ApplicationSet{}
+		err := yaml.Unmarshal([]byte(`apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - git:
+      repoURL: `+upstream+`
+      revision: master
+      directories:
+      - path: components/*
+  template:
+    metadata:
+      name: '{{path.basename}}'
+    spec:
+      source:
+        repoURL: `+upstream+`
+        path: '{{path}}'
+        targetRevision: master
+`), appset)
+		require.NoError(t, err)
+
+		// when
+		count := rewrites.ApplicationSet(appset)
+
+		// then
+		assert.Equal(t, 2, count)
+		assert.Equal(t, fork, appset.Spec.Template.Spec.Source.RepoURL)
+		assert.Equal(t, "my-branch", appset.Spec.Template.Spec.Source.TargetRevision)
+		assert.Equal(t, fork, appset.Spec.Generators[0].Git.RepoURL)
+		assert.Equal(t, "my-branch", appset.Spec.Generators[0].Git.Revision)
+	})
+
+	t.Run("ApplicationSet with nested generators", func(t *testing.T) {
+		// given
+		appset := &argocdv1alpha1.ApplicationSet{}
+		err := yaml.Unmarshal([]byte(`apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - matrix:
+      generators:
+      - git:
+          repoURL: `+upstream+`
+          revision: master
+          directories:
+          - path: components/*
+      - merge:
+          mergeKeys:
+          - name
+          generators:
+          - clusters: {}
+          - git:
+              repoURL: `+upstream+`
+              revision: master
+              files:
+              - path: clusters/*.yaml
+          - git:
+              repoURL: `+thirdParty+`
+              revision: master
+              files:
+              - path: clusters/*.yaml
+  template:
+    metadata:
+      name: '{{path.basename}}-{{name}}'
+    spec:
+      source:
+        repoURL: `+thirdParty+`
+        path: '{{path}}'
+`), appset)
+		require.NoError(t, err)
+
+		// when
+		count := rewrites.ApplicationSet(appset)
+
+		// then
+		assert.Equal(t, 2, count)
+		matrix := appset.Spec.Generators[0].Matrix
+		assert.Equal(t, fork, matrix.Generators[0].Git.RepoURL)
+		assert.Equal(t, "my-branch", matrix.Generators[0].Git.Revision)
+		merge, err := argocdv1alpha1.ToNestedMergeGenerator(matrix.Generators[1].Merge)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name"}, merge.MergeKeys)
+		assert.Equal(t, fork, merge.Generators[1].Git.RepoURL)
+		assert.Equal(t, "my-branch", merge.Generators[1].Git.Revision)
+		assert.Equal(t, thirdParty, merge.Generators[2].Git.RepoURL)
+		assert.Equal(t, "master", merge.Generators[2].Git.Revision)
+		assert.Equal(t, thirdParty, appset.Spec.Template.Spec.Source.RepoURL)
+	})
+}
+
+func TestOverrideRepository(t *testing.T) {
+
+	t.Run("single-source Application", func(t *testing.T) {
+		// given
+		app := &argocdv1alpha1.Application{}
+		err := yaml.Unmarshal([]byte(`apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  source:
+    repoURL: `+upstream+`
+    path: components/cookie
+    targetRevision: master
+`), app)
+		require.NoError(t, err)
+
+		// when
+		count := applications.OverrideRepository(&app.Spec, fork, "my-branch")
+
+		// then
+		assert.Equal(t, 1, count)
+		assert.Equal(t, fork, app.Spec.Source.RepoURL)
+		assert.Equal(t, "my-branch", app.Spec.Source.TargetRevision)
+	})
+
+	t.Run("multi-source ApplicationSet", func(t *testing.T) {
+		// given
+		appset := &argocdv1alpha1.ApplicationSet{}
+		err := yaml.Unmarshal([]byte(`apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - list:
+      elements:
+      - cluster: member-1
+  template:
+    metadata:
+      name: 'pasta-{{cluster}}'
+    spec:
+      sources:
+      - repoURL: `+upstream+`
+        path: components/pasta
+        targetRevision: master
+      - repoURL: https://charts.example.com
+        chart: pasta
+        targetRevision: 1.0.0
+`), appset)
+		require.NoError(t, err)
+
+		// when
+		count := applications.OverrideRepository(&appset.Spec.Template.Spec, fork, "my-branch")
+
+		// then
+		assert.Equal(t, 1, count)
+		assert.Nil(t, appset.Spec.Template.Spec.Source)
+		assert.Equal(t, fork, appset.Spec.Template.Spec.Sources[0].RepoURL)
+		assert.Equal(t, "my-branch", appset.Spec.Template.Spec.Sources[0].TargetRevision)
+		// Helm chart unchanged
+		assert.Equal(t, "https://charts.example.com", appset.Spec.Template.Spec.Sources[1].RepoURL)
+		assert.Equal(t, "1.0.0", appset.Spec.Template.Spec.Sources[1].TargetRevision)
+	})
+}
+
+func TestRewriteRepositories(t *testing.T) {
+
+	cookie := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  sources:
+  - repoURL: ` + upstream + ` # upstream
+    path: components/cookie
+  - repoURL: ` + thirdParty + `
+    path: manifests
+    targetRevision: v2.12.4
+`
+	pasta := `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - git:
+      repoURL: ` + upstream + `
+      revision: master
+      directories:
+      - path: components/*
+  template:
+    metadata:
+      name: '{{path.basename}}'
+    spec:
+      destination:
+        server: https://kubernetes.default.svc
+      source:
+        repoURL: ` + upstream + `
+        path: '{{path}}'
+        targetRevision: master
+`
+	files := map[string]string{
+		"/path/to/apps/app-cookie.yaml":   cookie,
+		"/path/to/apps/appset-pasta.yaml": pasta,
+	}
+	rewrites := applications.RepoRewrites{
+		{From: upstream, To: fork, TargetRevision: "my-branch"},
+	}
+	logger := log.New(os.Stderr)
+
+	t.Run("write", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		changes, err := applications.RewriteRepositories(logger, afs, "/path/to/apps", rewrites, true)
+
+		// then
+		require.NoError(t, err)
+		fields := []string{}
+		for _, c := range changes {
+			fields = append(fields, c.Name+":"+c.Field)
+		}
+		assert.Equal(t, []string{
+			"cookie:spec.sources[0].repoURL",
+			"cookie:spec.sources[0].targetRevision",
+			"pasta:spec.template.spec.source.repoURL",
+			"pasta:spec.template.spec.source.targetRevision",
+			"pasta:spec.generators[0].git.repoURL",
+			"pasta:spec.generators[0].git.revision",
+		}, fields)
+		data, err := afs.ReadFile("/path/to/apps/app-cookie.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  sources:
+  - repoURL: `+fork+` # upstream
+    path: components/cookie
+    targetRevision: my-branch
+  - repoURL: `+thirdParty+`
+    path: manifests
+    targetRevision: v2.12.4
+`, string(data))
+		data, err = afs.ReadFile("/path/to/apps/appset-pasta.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - git:
+      repoURL: `+fork+`
+      revision: my-branch
+      directories:
+      - path: components/*
+  template:
+    metadata:
+      name: '{{path.basename}}'
+    spec:
+      destination:
+        server: https://kubernetes.default.svc
+      source:
+        repoURL: `+fork+`
+        path: '{{path}}'
+        targetRevision: my-branch
+`, string(data))
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		changes, err := applications.RewriteRepositories(logger, afs, "/path/to/apps", rewrites, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, changes, 6)
+		data, err := afs.ReadFile("/path/to/apps/app-cookie.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, cookie, string(data))
+	})
+	t.Run("nested generators", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/appset-pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - matrix:
+      generators:
+      - git:
+          repoURL: ` + upstream + `
+          revision: master
+          directories:
+          - path: components/*
+      - merge:
+          mergeKeys:
+          - name
+          generators:
+          - clusters: {}
+          - git:
+              repoURL: ` + upstream + ` # clusters
+              files:
+              - path: clusters/*.yaml
+  template:
+    metadata:
+      name: '{{path.basename}}-{{name}}'
+    spec:
+      destination:
+        server: '{{server}}'
+      source:
+        repoURL: ` + thirdParty + `
+        path: '{{path}}'
+`,
+		})
+
+		// when
+		changes, err := applications.RewriteRepositories(logger, afs, "/path/to/apps", rewrites, true)
+
+		// then
+		require.NoError(t, err)
+		fields := []string{}
+		for _, c := range changes {
+			fields = append(fields, c.Name+":"+c.Field)
+		}
+		assert.Equal(t, []string{
+			"pasta:spec.generators[0].matrix.generators[0].git.repoURL",
+			"pasta:spec.generators[0].matrix.generators[0].git.revision",
+			"pasta:spec.generators[0].matrix.generators[1].merge.generators[1].git.repoURL",
+			"pasta:spec.generators[0].matrix.generators[1].merge.generators[1].git.revision",
+		}, fields)
+		data, err := afs.ReadFile("/path/to/apps/appset-pasta.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - matrix:
+      generators:
+      - git:
+          repoURL: `+fork+`
+          revision: my-branch
+          directories:
+          - path: components/*
+      - merge:
+          mergeKeys:
+          - name
+          generators:
+          - clusters: {}
+          - git:
+              repoURL: `+fork+` # clusters
+              files:
+              - path: clusters/*.yaml
+              revision: my-branch
+  template:
+    metadata:
+      name: '{{path.basename}}-{{name}}'
+    spec:
+      destination:
+        server: '{{server}}'
+      source:
+        repoURL: `+thirdParty+`
+        path: '{{path}}'
+`, string(data))
+	})
+}
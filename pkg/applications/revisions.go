@@ -0,0 +1,202 @@
+package applications
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// SourceChange a change of a field of a source of an Application or ApplicationSet
+type SourceChange struct {
+	// Path of the file which contains the Application or ApplicationSet
+	Path string
+	Kind string
+	Name string
+	// Field path of the field which was changed (eg: `spec.sources[1].targetRevision`)
+	Field string
+}
+
+// SetTargetRevision replaces the `from` target revision with the `to` target revision in the sources (`spec.source`
+// and `spec.sources`) of the Applications and the templates of the ApplicationSets found in the given directory
+// (see `ListApplicationFiles`).
+// The files are edited in place (comments are preserved), only when `write` is true.
+// Returns the changes (or the changes which would be made), in the order of the files and of the objects in each file.
+func SetTargetRevision(logger *log.Logger, afs afero.Afero, baseDir, from, to string, write bool) ([]SourceChange, error) {
+	return editSources(logger, afs, baseDir, write, func(node *kyaml.RNode) ([]string, error) {
+		fields := []string{}
+		err := visitSources(node, func(source *kyaml.RNode, path string) error {
+			if setField(source, "targetRevision", from, to) {
+				fields = append(fields, path+".targetRevision")
+			}
+			return nil
+		})
+		return fields, err
+	})
+}
+
+// applies the given edit function on the Applications and ApplicationSets found in the given directory
+// (see `ListApplicationFiles`). The edit function returns the paths of the fields which were changed.
+// The files are edited in place (comments are preserved), only when `write` is true. Only the documents which changed
+// are re-encoded, the other documents of the files are kept as-is.
+func editSources(logger *log.Logger, afs afero.Afero, baseDir string, write bool, edit func(*kyaml.RNode) ([]string, error)) ([]SourceChange, error) {
+	files, err := ListApplicationFiles(logger, afs, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	changes := []SourceChange{}
+	for _, path := range files {
+		data, err := afs.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		out := &bytes.Buffer{}
+		fileChanges := []SourceChange{}
+		for _, doc := range splitDocuments(data) {
+			result, docChanges, err := editDocument(path, doc.body, edit)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(doc.separator)
+			if len(docChanges) == 0 {
+				out.Write(doc.body)
+				continue
+			}
+			out.Write(result)
+			fileChanges = append(fileChanges, docChanges...)
+		}
+		if len(fileChanges) == 0 {
+			continue
+		}
+		changes = append(changes, fileChanges...)
+		if !write {
+			continue
+		}
+		logger.Debug("editing file", "path", path, "changes", len(fileChanges))
+		info, err := afs.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := afs.WriteFile(path, out.Bytes(), info.Mode()); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// applies the given edit function on the object of the given document (of the file at the given path).
+// Returns the re-encoded document and the changes, if any.
+func editDocument(path string, data []byte, edit func(*kyaml.RNode) ([]string, error)) ([]byte, []SourceChange, error) {
+	out := &bytes.Buffer{}
+	changes := []SourceChange{}
+	rw := &kio.ByteReadWriter{
+		Reader:            bytes.NewReader(data),
+		Writer:            out,
+		PreserveSeqIndent: true,
+	}
+	if err := (kio.Pipeline{
+		Inputs: []kio.Reader{rw},
+		Filters: []kio.Filter{kio.FilterFunc(func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			for _, n := range nodes {
+				fields, err := edit(n)
+				if err != nil {
+					return nil, fmt.Errorf("failed to edit %s '%s' in %s: %w", n.GetKind(), n.GetName(), path, err)
+				}
+				for _, f := range fields {
+					changes = append(changes, SourceChange{
+						Path:  path,
+						Kind:  n.GetKind(),
+						Name:  n.GetName(),
+						Field: f,
+					})
+				}
+			}
+			return nodes, nil
+		})},
+		Outputs: []kio.Writer{rw},
+	}).Execute(); err != nil {
+		return nil, nil, err
+	}
+	return out.Bytes(), changes, nil
+}
+
+// separator of the documents of a YAML stream, possibly followed by a comment
+var documentSeparator = regexp.MustCompile(`(?m)^---([ \t].*)?(\n|$)`)
+
+// document a document of a YAML stream, with the separator which precedes it (if any)
+type document struct {
+	separator []byte
+	body      []byte
+}
+
+// splits the given YAML stream into its documents, so that joining the separators and the bodies
+// gives back the original stream
+func splitDocuments(data []byte) []document {
+	docs := []document{}
+	var separator []byte
+	start := 0
+	for _, loc := range documentSeparator.FindAllIndex(data, -1) {
+		if loc[0] > 0 || separator != nil {
+			docs = append(docs, document{separator: separator, body: data[start:loc[0]]})
+		}
+		separator = data[loc[0]:loc[1]]
+		start = loc[1]
+	}
+	return append(docs, document{separator: separator, body: data[start:]})
+}
+
+// calls the given function (until it fails) on each source (`spec.source` and `spec.sources`) of the given Application,
+// or of the template of the given ApplicationSet, with the path of the source. Other kinds of objects are ignored.
+func visitSources(node *kyaml.RNode, fn func(source *kyaml.RNode, path string) error) error {
+	if !IsArgoCD(node.GetApiVersion()) {
+		return nil
+	}
+	var spec []string
+	switch node.GetKind() {
+	case "Application":
+		spec = []string{"spec"}
+	case "ApplicationSet":
+		spec = []string{"spec", "template", "spec"}
+	default:
+		return nil
+	}
+	prefix := strings.Join(spec, ".")
+	source, err := node.Pipe(kyaml.Lookup(append(spec, "source")...))
+	if err != nil {
+		return err
+	}
+	if source != nil {
+		if err := fn(source, prefix+".source"); err != nil {
+			return err
+		}
+	}
+	sources, err := node.Pipe(kyaml.Lookup(append(spec, "sources")...))
+	if err != nil || sources == nil {
+		return err
+	}
+	elements, err := sources.Elements()
+	if err != nil {
+		return err
+	}
+	for i, s := range elements {
+		if err := fn(s, fmt.Sprintf("%s.sources[%d]", prefix, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaces the value of the given field with `to` if it is `from`. Returns true if the field was changed
+func setField(node *kyaml.RNode, field, from, to string) bool {
+	f := node.Field(field)
+	if f == nil || f.Value.YNode().Value != from || from == to {
+		return false
+	}
+	f.Value.YNode().Value = to
+	return true
+}
@@ -0,0 +1,280 @@
+package applications_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTargetRevision(t *testing.T) {
+
+	cookie := `# the cookie app
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie
+    targetRevision: main # the main branch
+`
+	pasta := `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  sources:
+    - repoURL: https://charts.example.com
+      chart: pasta
+      targetRevision: 1.0.0
+    - repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+      path: components/pasta
+      targetRevision: main
+`
+	pizza := `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pizza
+spec:
+  generators:
+  - list:
+      elements:
+      - cluster: member-1
+  template:
+    metadata:
+      name: 'pizza-{{cluster}}'
+    spec:
+      destination:
+        server: https://kubernetes.default.svc
+      source:
+        repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+        path: components/pizza
+        targetRevision: main
+`
+	files := map[string]string{
+		"/path/to/apps/app-cookie.yaml":   cookie,
+		"/path/to/apps/app-pasta.yaml":    pasta,
+		"/path/to/apps/appset-pizza.yaml": pizza,
+		// an Application targeting a cluster by name, after another document
+		"/path/to/apps/app-cake.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cake
+data:
+  targetRevision: main
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cake
+spec:
+  destination:
+    name: member-1
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cake
+    targetRevision: main
+`,
+		"/path/to/apps/kustomization.yaml": `resources:
+- app-cake.yaml
+- app-cookie.yaml
+- app-pasta.yaml
+- appset-pizza.yaml
+`,
+	}
+	logger := log.New(os.Stderr)
+
+	t.Run("write", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		changes, err := applications.SetTargetRevision(logger, afs, "/path/to/apps", "main", "release-1.0", true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []applications.SourceChange{
+			{
+				Path:  "/path/to/apps/app-cake.yaml",
+				Kind:  "Application",
+				Name:  "cake",
+				Field: "spec.source.targetRevision",
+			},
+			{
+				Path:  "/path/to/apps/app-cookie.yaml",
+				Kind:  "Application",
+				Name:  "cookie",
+				Field: "spec.source.targetRevision",
+			},
+			{
+				Path:  "/path/to/apps/app-pasta.yaml",
+				Kind:  "Application",
+				Name:  "pasta",
+				Field: "spec.sources[1].targetRevision",
+			},
+			{
+				Path:  "/path/to/apps/appset-pizza.yaml",
+				Kind:  "ApplicationSet",
+				Name:  "pizza",
+				Field: "spec.template.spec.source.targetRevision",
+			},
+		}, changes)
+		data, err := afs.ReadFile("/path/to/apps/app-cookie.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `# the cookie app
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie
+    targetRevision: release-1.0 # the main branch
+`, string(data))
+		// indentation of the lists is preserved
+		data, err = afs.ReadFile("/path/to/apps/app-pasta.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  sources:
+    - repoURL: https://charts.example.com
+      chart: pasta
+      targetRevision: 1.0.0
+    - repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+      path: components/pasta
+      targetRevision: release-1.0
+`, string(data))
+		data, err = afs.ReadFile("/path/to/apps/appset-pizza.yaml")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "        targetRevision: release-1.0\n")
+		assert.Contains(t, string(data), "      name: 'pizza-{{cluster}}'\n")
+		// other documents are left unchanged
+		data, err = afs.ReadFile("/path/to/apps/app-cake.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cake
+data:
+  targetRevision: main
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cake
+spec:
+  destination:
+    name: member-1
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cake
+    targetRevision: release-1.0
+`, string(data))
+	})
+
+	t.Run("other documents kept as-is", func(t *testing.T) {
+		// given
+		candy := `# the candy app
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: candy
+spec:
+  destination:
+    name: member-1
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/candy
+    targetRevision: main
+`
+		// with a different indentation, and a comment after the separator
+		other := `--- # the candy config
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: candy
+    labels:
+        - not
+        - a
+        - map
+data:
+    targetRevision: main
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+    name: candy-extra
+spec:
+    destination:
+        name: member-1
+    source:
+        repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+        path: components/candy
+        targetRevision: release-0.9
+`
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/app-candy.yaml": candy + other,
+		})
+
+		// when
+		changes, err := applications.SetTargetRevision(logger, afs, "/path/to/apps", "main", "release-1.0", true)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "candy", changes[0].Name)
+		data, err := afs.ReadFile("/path/to/apps/app-candy.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, strings.Replace(candy, "targetRevision: main", "targetRevision: release-1.0", 1)+other, string(data))
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		changes, err := applications.SetTargetRevision(logger, afs, "/path/to/apps", "main", "release-1.0", false)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, changes, 4)
+		data, err := afs.ReadFile("/path/to/apps/app-cookie.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, cookie, string(data))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		changes, err := applications.SetTargetRevision(logger, afs, "/path/to/apps", "release-0.9", "release-1.0", true)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+		data, err := afs.ReadFile("/path/to/apps/app-pasta.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, pasta, string(data))
+	})
+}
@@ -0,0 +1,43 @@
+package bundle
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Apply creates the given objects in the cluster, or updates them if they already exist.
+// When `dryRun` is true, the requests are sent in dry-run mode (ie, the objects are validated by the API server
+// but not persisted).
+func Apply(ctx context.Context, logger *log.Logger, cl runtimeclient.Client, objs []*unstructured.Unstructured, dryRun bool) error {
+	createOpts := []runtimeclient.CreateOption{}
+	updateOpts := []runtimeclient.UpdateOption{}
+	if dryRun {
+		createOpts = append(createOpts, runtimeclient.DryRunAll)
+		updateOpts = append(updateOpts, runtimeclient.DryRunAll)
+	}
+	for _, obj := range objs {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		err := cl.Get(ctx, runtimeclient.ObjectKeyFromObject(obj), existing)
+		switch {
+		case err == nil:
+			obj.SetResourceVersion(existing.GetResourceVersion())
+			if err := cl.Update(ctx, obj, updateOpts...); err != nil {
+				return err
+			}
+			logger.Info("updated", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+			continue
+		case !apierrors.IsNotFound(err):
+			return err
+		}
+		if err := cl.Create(ctx, obj, createOpts...); err != nil {
+			return err
+		}
+		logger.Info("created", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+	}
+	return nil
+}
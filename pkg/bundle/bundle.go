@@ -0,0 +1,252 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// IndexFile name of the file with the index of the manifests, at the root of the bundle
+const IndexFile = "index.yaml"
+
+// name of the directory in which the cluster-scoped objects are written (instead of the directory of their namespace)
+const clusterScopedDir = "_cluster"
+
+// Bundle the manifests rendered by a set of Applications, grouped by destination cluster and namespace:
+// each object is written in the `<cluster>/<namespace>/<filename>` file (or `<cluster>/_cluster/<filename>` for cluster-scoped
+// objects, see `render.Filename`), and the index lists the files with their checksum.
+type Bundle struct {
+	Index Index
+	// contents of the files, by path (relative to the root of the bundle)
+	files map[string][]byte
+}
+
+// Index the manifests of the bundle
+type Index struct {
+	// Entries sorted by path
+	Entries []Entry `json:"entries"`
+}
+
+// Entry an object of the bundle
+type Entry struct {
+	// Path of the file (relative to the root of the bundle)
+	Path string `json:"path"`
+	// SHA256 checksum of the file
+	SHA256 string `json:"sha256"`
+	// Application which rendered the object
+	Application string `json:"application"`
+	// Cluster name of the destination cluster of the Application
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace,omitempty"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// Export renders the Applications found in the `apps` paths (relative to the base directory), including the Applications
+// generated by the ApplicationSets, using the local sources, and returns the bundle of the rendered objects.
+// The name of the destination clusters is resolved with the given inventory, whether the Applications refer to them
+// by server URL or by name.
+// Namespaced objects without a namespace are set in the destination namespace of their Application.
+// Applications whose source path contains a `{{...}}` placeholder are skipped.
+func Export(logger *log.Logger, afs afero.Afero, baseDir string, apps []string, inventory clusters.Inventory) (*Bundle, error) {
+	all := map[string]*argocdv1alpha1.Application{}
+	for _, p := range apps {
+		as, appsets, err := applications.ListApplications(logger, afs, filepath.Join(baseDir, p))
+		if err != nil {
+			return nil, err
+		}
+		for _, app := range as {
+			all[app.Name] = app
+		}
+		for _, appset := range appsets {
+			generated, err := applications.GenerateApplications(appset)
+			if err != nil {
+				return nil, err
+			}
+			for _, app := range generated {
+				all[app.Name] = app
+			}
+		}
+	}
+	names := make([]string, 0, len(all))
+	for n := range all {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	fsys, err := validation.NewInMemoryFS(logger, afs, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{
+		files: map[string][]byte{},
+	}
+	owners := map[string]string{}
+	for _, name := range names {
+		app := all[name]
+		if isTemplated(app) {
+			logger.Warn("skipping templated Application", "name", name)
+			continue
+		}
+		logger.Debug("rendering Application", "name", name)
+		objs, err := render.Application(logger, fsys, baseDir, app)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render Application '%s': %w", name, err)
+		}
+		cluster := clusterName(app.Spec.Destination, inventory)
+		namespaced := validation.Namespaced(objs)
+		for _, obj := range objs {
+			dir := clusterScopedDir
+			if namespaced(obj) {
+				if obj.GetNamespace() == "" {
+					namespace := app.Spec.Destination.Namespace
+					if namespace == "" {
+						// same as `kubectl apply` with the default context
+						namespace = corev1.NamespaceDefault
+					}
+					obj.SetNamespace(namespace)
+				}
+				dir = obj.GetNamespace()
+			}
+			p := path.Join(cluster, dir, render.Filename(obj))
+			if owner, found := owners[p]; found {
+				return nil, fmt.Errorf("%s/%s is rendered by both Applications '%s' and '%s'", obj.GetKind(), obj.GetName(), owner, name)
+			}
+			owners[p] = name
+			data, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return nil, err
+			}
+			b.files[p] = data
+			b.Index.Entries = append(b.Index.Entries, Entry{
+				Path:        p,
+				SHA256:      checksum(data),
+				Application: name,
+				Cluster:     cluster,
+				Namespace:   obj.GetNamespace(),
+				APIVersion:  obj.GetAPIVersion(),
+				Kind:        obj.GetKind(),
+				Name:        obj.GetName(),
+			})
+		}
+	}
+	sort.Slice(b.Index.Entries, func(i, j int) bool {
+		return b.Index.Entries[i].Path < b.Index.Entries[j].Path
+	})
+	return b, nil
+}
+
+// Clusters returns the sorted names of the clusters of the bundle
+func (b *Bundle) Clusters() []string {
+	names := []string{}
+	for _, e := range b.Index.Entries {
+		if !slices.Contains(names, e.Cluster) {
+			names = append(names, e.Cluster)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Objects returns the objects of the bundle for the given cluster, in the order in which they should be applied:
+// Namespaces first, then CustomResourceDefinitions, then the other objects (in the order of the index)
+func (b *Bundle) Objects(cluster string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+	for _, e := range b.Index.Entries {
+		if e.Cluster != cluster {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(b.files[e.Path], &obj.Object); err != nil {
+			return nil, fmt.Errorf("invalid manifest in %s: %w", e.Path, err)
+		}
+		objs = append(objs, obj)
+	}
+	rank := func(obj *unstructured.Unstructured) int {
+		switch obj.GroupVersionKind().GroupKind().String() {
+		case "Namespace":
+			return 0
+		case "CustomResourceDefinition.apiextensions.k8s.io":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return rank(objs[i]) < rank(objs[j])
+	})
+	return objs, nil
+}
+
+// verifies that the files of the bundle match the index
+func (b *Bundle) verify() error {
+	indexed := map[string]bool{}
+	for _, e := range b.Index.Entries {
+		data, found := b.files[e.Path]
+		if !found {
+			return fmt.Errorf("missing file in bundle: %s", e.Path)
+		}
+		if checksum(data) != e.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s", e.Path)
+		}
+		indexed[e.Path] = true
+	}
+	for p := range b.files {
+		if !indexed[p] {
+			return fmt.Errorf("unexpected file in bundle: %s", p)
+		}
+	}
+	return nil
+}
+
+// returns the name of the destination cluster: its name in the inventory (looked up by server URL or by name),
+// its name in the Application, or the host of its server URL
+func clusterName(dest argocdv1alpha1.ApplicationDestination, inventory clusters.Inventory) string {
+	if c, found := inventory.Lookup(dest.Server, dest.Name); found {
+		return c.Name
+	}
+	if dest.Name != "" {
+		return dest.Name
+	}
+	if dest.Server == clusters.InCluster.Server {
+		return clusters.InCluster.Name
+	}
+	if u, err := url.Parse(dest.Server); err == nil && u.Host != "" {
+		return strings.ReplaceAll(u.Host, ":", "_")
+	}
+	return strings.NewReplacer("/", "_", ":", "_").Replace(dest.Server)
+}
+
+// returns true if the path of one of the sources of the given Application contains a `{{...}}` placeholder
+func isTemplated(app *argocdv1alpha1.Application) bool {
+	for _, source := range app.Spec.GetSources() {
+		if applications.IsTemplated(source.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
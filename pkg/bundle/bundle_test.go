@@ -0,0 +1,409 @@
+package bundle_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/bundle"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/scheme"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExport(t *testing.T) {
+
+	logger := log.New(os.Stderr)
+
+	files := map[string]string{
+		"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie
+`,
+		"/path/to/apps/appset-pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - list:
+      elements:
+      - server: https://api.member-1:6443
+        cluster: member-1
+  template:
+    metadata:
+      name: 'pasta-{{cluster}}'
+    spec:
+      destination:
+        server: '{{server}}'
+        namespace: pasta
+      source:
+        repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+        path: components/pasta
+`,
+		"/path/to/components/cookie/kustomization.yaml": `resources:
+- configmap.yaml
+- clusterrole.yaml
+- namespace.yaml
+`,
+		"/path/to/components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: chocolate
+`,
+		"/path/to/components/cookie/clusterrole.yaml": `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie
+`,
+		"/path/to/components/cookie/namespace.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: cookie
+`,
+		"/path/to/components/pasta/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta
+  namespace: spaghetti
+data:
+  sauce: tomato
+`,
+	}
+
+	t.Run("with inventory", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+		inventory := clusters.Inventory{
+			clusters.InCluster,
+			{Name: "member-1", Server: "https://api.member-1:6443"},
+		}
+
+		// when
+		b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, inventory)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"in-cluster", "member-1"}, b.Clusters())
+		paths := []string{}
+		for _, e := range b.Index.Entries {
+			paths = append(paths, e.Path)
+			assert.Len(t, e.SHA256, 64)
+		}
+		assert.Equal(t, []string{
+			"in-cluster/_cluster/clusterrole.rbac.authorization.k8s.io_cookie.yaml",
+			"in-cluster/_cluster/namespace_cookie.yaml",
+			"in-cluster/cookie/configmap_cookie_cookie.yaml",
+			"member-1/spaghetti/configmap_spaghetti_pasta.yaml",
+		}, paths)
+		assert.Equal(t, bundle.Entry{
+			Path:        "in-cluster/cookie/configmap_cookie_cookie.yaml",
+			SHA256:      b.Index.Entries[2].SHA256,
+			Application: "cookie",
+			Cluster:     "in-cluster",
+			Namespace:   "cookie",
+			APIVersion:  "v1",
+			Kind:        "ConfigMap",
+			Name:        "cookie",
+		}, b.Index.Entries[2])
+		assert.Equal(t, "pasta-member-1", b.Index.Entries[3].Application)
+	})
+
+	t.Run("without inventory", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api.member-1_6443", "in-cluster"}, b.Clusters())
+	})
+
+	t.Run("application targeting a cluster by name", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app-candy.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: candy
+spec:
+  destination:
+    name: member-1
+    namespace: candy
+  source:
+    path: components/candy
+`,
+			"/path/to/components/candy/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: candy
+`,
+		})
+		inventory := clusters.Inventory{
+			clusters.InCluster,
+			{Name: "member-1", Server: "https://api.member-1:6443"},
+		}
+
+		// when
+		b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, inventory)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"in-cluster", "member-1"}, b.Clusters())
+		assert.Equal(t, bundle.Entry{
+			Path:        "member-1/candy/configmap_candy_candy.yaml",
+			SHA256:      b.Index.Entries[3].SHA256,
+			Application: "candy",
+			Cluster:     "member-1",
+			Namespace:   "candy",
+			APIVersion:  "v1",
+			Kind:        "ConfigMap",
+			Name:        "candy",
+		}, b.Index.Entries[3])
+	})
+
+	t.Run("object rendered by 2 Applications on the same cluster by server and by name", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app-pasta-copy.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta-copy
+spec:
+  destination:
+    name: member-1
+  source:
+    path: components/pasta
+`,
+		})
+		inventory := clusters.Inventory{
+			clusters.InCluster,
+			{Name: "member-1", Server: "https://api.member-1:6443"},
+		}
+
+		// when
+		_, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, inventory)
+
+		// then
+		require.EqualError(t, err, "ConfigMap/pasta is rendered by both Applications 'pasta-copy' and 'pasta-member-1'")
+	})
+
+	t.Run("object rendered by 2 Applications", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app-cookie-copy.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie-copy
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  source:
+    path: components/cookie
+`,
+		})
+
+		// when
+		_, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, nil)
+
+		// then
+		require.EqualError(t, err, "ConfigMap/cookie is rendered by both Applications 'cookie' and 'cookie-copy'")
+	})
+}
+
+func TestWriteAndRead(t *testing.T) {
+
+	logger := log.New(os.Stderr)
+
+	files := map[string]string{
+		"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie
+`,
+		"/path/to/components/cookie/kustomization.yaml": `resources:
+- configmap.yaml
+- clusterrole.yaml
+- namespace.yaml
+`,
+		"/path/to/components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: chocolate
+`,
+		"/path/to/components/cookie/clusterrole.yaml": `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie
+`,
+		"/path/to/components/cookie/namespace.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: cookie
+`,
+	}
+
+	for _, output := range []string{"/path/to/bundle", "/path/to/bundle.tar.gz"} {
+		t.Run(output, func(t *testing.T) {
+			// given
+			afs := test.NewFS(t, files)
+			b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, nil)
+			require.NoError(t, err)
+
+			// when
+			err = b.Write(afs, output)
+			require.NoError(t, err)
+			result, err := bundle.Read(afs, output)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, b.Index, result.Index)
+			objs, err := result.Objects("in-cluster")
+			require.NoError(t, err)
+			require.Len(t, objs, 3)
+			// namespaces first
+			assert.Equal(t, "Namespace", objs[0].GetKind())
+			assert.Equal(t, "ClusterRole", objs[1].GetKind())
+			assert.Equal(t, "ConfigMap", objs[2].GetKind())
+			assert.Equal(t, "cookie", objs[2].GetNamespace())
+		})
+	}
+
+	t.Run("tampered file", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+		b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, nil)
+		require.NoError(t, err)
+		err = b.Write(afs, "/path/to/bundle")
+		require.NoError(t, err)
+		err = afs.WriteFile("/path/to/bundle/in-cluster/cookie/configmap_cookie_cookie.yaml", []byte("apiVersion: v1\nkind: ConfigMap\n"), 0644)
+		require.NoError(t, err)
+
+		// when
+		_, err = bundle.Read(afs, "/path/to/bundle")
+
+		// then
+		require.EqualError(t, err, "invalid bundle /path/to/bundle: checksum mismatch for in-cluster/cookie/configmap_cookie_cookie.yaml")
+	})
+
+	t.Run("unexpected file", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+		b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, nil)
+		require.NoError(t, err)
+		err = b.Write(afs, "/path/to/bundle")
+		require.NoError(t, err)
+		err = afs.WriteFile("/path/to/bundle/in-cluster/cookie/secret_cookie_cookie.yaml", []byte("apiVersion: v1\nkind: Secret\n"), 0644)
+		require.NoError(t, err)
+
+		// when
+		_, err = bundle.Read(afs, "/path/to/bundle")
+
+		// then
+		require.EqualError(t, err, "invalid bundle /path/to/bundle: unexpected file in bundle: in-cluster/cookie/secret_cookie_cookie.yaml")
+	})
+}
+
+func TestApply(t *testing.T) {
+
+	// given
+	ctx := context.TODO()
+	logger := log.New(os.Stderr)
+	afs := test.NewFS(t, map[string]string{
+		"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie
+`,
+		"/path/to/components/cookie/kustomization.yaml": `resources:
+- configmap.yaml
+- clusterrole.yaml
+- namespace.yaml
+`,
+		"/path/to/components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: chocolate
+`,
+		"/path/to/components/cookie/clusterrole.yaml": `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie
+`,
+		"/path/to/components/cookie/namespace.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: cookie
+`,
+	})
+	b, err := bundle.Export(logger, afs, "/path/to", []string{"apps"}, nil)
+	require.NoError(t, err)
+	objs, err := b.Objects("in-cluster")
+	require.NoError(t, err)
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion("v1")
+	existing.SetKind("ConfigMap")
+	existing.SetNamespace("cookie")
+	existing.SetName("cookie")
+	existing.Object["data"] = map[string]interface{}{"flavor": "vanilla"}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).
+		WithObjects(existing).
+		Build()
+
+	// when
+	err = bundle.Apply(ctx, logger, cl, objs, false)
+
+	// then
+	require.NoError(t, err)
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	err = cl.Get(ctx, runtimeclient.ObjectKey{Namespace: "cookie", Name: "cookie"}, cm)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"flavor": "chocolate"}, cm.Object["data"])
+	ns := &unstructured.Unstructured{}
+	ns.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+	err = cl.Get(ctx, runtimeclient.ObjectKey{Name: "cookie"}, ns)
+	require.NoError(t, err)
+}
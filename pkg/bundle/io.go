@@ -0,0 +1,149 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// IsTarball returns true if the given path is the path of a gzipped tarball (ie: with a `.tar.gz` or `.tgz` extension)
+func IsTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// Write writes the bundle in the given directory, or in a gzipped tarball if the path has a `.tar.gz` or `.tgz` extension.
+// The index is written in the `index.yaml` file, at the root of the bundle.
+func (b *Bundle) Write(afs afero.Afero, path string) error {
+	index, err := yaml.Marshal(b.Index)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(b.files))
+	for p := range b.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	if !IsTarball(path) {
+		for _, p := range paths {
+			if err := writeFile(afs, filepath.Join(path, filepath.FromSlash(p)), b.files[p]); err != nil {
+				return err
+			}
+		}
+		return writeFile(afs, filepath.Join(path, IndexFile), index)
+	}
+
+	out := &bytes.Buffer{}
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+	for _, f := range append([]string{IndexFile}, paths...) {
+		data := index
+		if f != IndexFile {
+			data = b.files[f]
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     f,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+			// fixed modification time, so that the tarball of the same manifests is always the same
+			ModTime: time.Unix(0, 0).UTC(),
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return writeFile(afs, path, out.Bytes())
+}
+
+// Read reads the bundle in the given directory or gzipped tarball (see `Write`),
+// and verifies that its files match the checksums of the index
+func Read(afs afero.Afero, path string) (*Bundle, error) {
+	files := map[string][]byte{}
+	if IsTarball(path) {
+		f, err := afs.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+		}
+		tr := tar.NewReader(gr)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+			}
+			if h.Typeflag != tar.TypeReg {
+				continue
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			files[filepath.ToSlash(filepath.Clean(h.Name))] = data
+		}
+	} else {
+		if err := afs.Walk(path, func(p string, info iofs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			data, err := afs.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(path, p)
+			files[filepath.ToSlash(rel)] = data
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	index, found := files[IndexFile]
+	if !found {
+		return nil, fmt.Errorf("invalid bundle %s: missing %s", path, IndexFile)
+	}
+	delete(files, IndexFile)
+	b := &Bundle{
+		files: files,
+	}
+	if err := yaml.Unmarshal(index, &b.Index); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	if err := b.verify(); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	return b, nil
+}
+
+func writeFile(afs afero.Afero, path string, data []byte) error {
+	if err := afs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return afs.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,119 @@
+package clusters
+
+import (
+	"fmt"
+	iofs "io/fs"
+	"path/filepath"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// InCluster is the cluster in which Argo CD runs, which is always known to Argo CD
+// even when there is no Secret for it
+var InCluster = Cluster{
+	Name:   "in-cluster",
+	Server: "https://kubernetes.default.svc",
+}
+
+// the label that Argo CD uses to identify the cluster Secrets
+const secretTypeLabel = "argocd.argoproj.io/secret-type"
+
+// Cluster a cluster to which Argo CD can deploy Applications
+type Cluster struct {
+	Name   string `json:"name"`
+	Server string `json:"server"`
+}
+
+// Inventory the clusters known to Argo CD
+type Inventory []Cluster
+
+// Load returns the clusters declared in the YAML file(s) at the given path, which can be a file or a directory.
+// Each file contains either Argo CD cluster Secrets (ie, with the `argocd.argoproj.io/secret-type: cluster` label)
+// or a simple list of clusters with a `name` and a `server` (eg: `[{name: member-1, server: https://api.member-1:6443}]`).
+// The `in-cluster` cluster is always part of the inventory.
+func Load(logger *log.Logger, afs afero.Afero, path string) (Inventory, error) {
+	logger.Info("👀 looking for clusters", "path", path)
+	inventory := Inventory{InCluster}
+	err := afs.Walk(path, func(path string, info iofs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filepath.Ext(info.Name()) != ".yaml" && filepath.Ext(info.Name()) != ".yml") {
+			return nil
+		}
+		data, err := afs.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		logger.Debug("checking contents", "path", path)
+		list := []Cluster{}
+		if err := yaml.Unmarshal(data, &list); err == nil {
+			inventory = append(inventory, list...)
+			return nil
+		}
+		objs, err := kube.SplitYAML(data)
+		if err != nil {
+			return fmt.Errorf("invalid cluster inventory in %s: %w", path, err)
+		}
+		for _, obj := range objs {
+			if obj.GetKind() != "Secret" || obj.GetLabels()[secretTypeLabel] != "cluster" {
+				continue
+			}
+			secret := &corev1.Secret{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, secret); err != nil {
+				return fmt.Errorf("invalid cluster Secret '%s' in %s: %w", obj.GetName(), path, err)
+			}
+			inventory = append(inventory, Cluster{
+				Name:   secretValue(secret, "name"),
+				Server: secretValue(secret, "server"),
+			})
+		}
+		return nil
+	})
+	return inventory, err
+}
+
+// returns the value of the given key in the `stringData` or in the `data` of the Secret
+func secretValue(secret *corev1.Secret, key string) string {
+	if v, found := secret.StringData[key]; found {
+		return v
+	}
+	return string(secret.Data[key])
+}
+
+// Names returns the names of the clusters in the inventory
+func (i Inventory) Names() []string {
+	names := make([]string, 0, len(i))
+	for _, c := range i {
+		if c.Name != "" {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// Servers returns the server URLs of the clusters in the inventory
+func (i Inventory) Servers() []string {
+	servers := make([]string, 0, len(i))
+	for _, c := range i {
+		if c.Server != "" {
+			servers = append(servers, c.Server)
+		}
+	}
+	return servers
+}
+
+// Lookup returns the cluster with the given server URL or name
+func (i Inventory) Lookup(server, name string) (Cluster, bool) {
+	for _, c := range i {
+		if (server != "" && c.Server == server) || (server == "" && name != "" && c.Name == name) {
+			return c, true
+		}
+	}
+	return Cluster{}, false
+}
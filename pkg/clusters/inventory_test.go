@@ -0,0 +1,91 @@
+package clusters_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+
+	t.Run("cluster secrets", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/clusters/member-1.yaml", []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: member-1
+  labels:
+    argocd.argoproj.io/secret-type: cluster
+stringData:
+  name: member-1
+  server: https://api.member-1:6443
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: member-2
+  labels:
+    argocd.argoproj.io/secret-type: cluster
+data:
+  name: bWVtYmVyLTI= # member-2
+  server: aHR0cHM6Ly9hcGkubWVtYmVyLTI6NjQ0Mw== # https://api.member-2:6443
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: repo
+  labels:
+    argocd.argoproj.io/secret-type: repository
+stringData:
+  url: https://github.com/codeready-toolchain/sandbox-argocd`), 0755)
+		require.NoError(t, err)
+		logger := log.New(os.Stdout)
+
+		// when
+		inventory, err := clusters.Load(logger, afs, "/path/to/clusters")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, clusters.Inventory{
+			clusters.InCluster,
+			{
+				Name:   "member-1",
+				Server: "https://api.member-1:6443",
+			},
+			{
+				Name:   "member-2",
+				Server: "https://api.member-2:6443",
+			},
+		}, inventory)
+	})
+
+	t.Run("list of clusters", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/clusters.yaml", []byte(`- name: member-1
+  server: https://api.member-1:6443
+- name: member-2
+  server: https://api.member-2:6443`), 0755)
+		require.NoError(t, err)
+		logger := log.New(os.Stdout)
+
+		// when
+		inventory, err := clusters.Load(logger, afs, "/path/to/clusters.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"in-cluster", "member-1", "member-2"}, inventory.Names())
+		assert.Equal(t, []string{"https://kubernetes.default.svc", "https://api.member-1:6443", "https://api.member-2:6443"}, inventory.Servers())
+	})
+}
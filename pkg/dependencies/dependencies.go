@@ -0,0 +1,239 @@
+package dependencies
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+)
+
+// Kind of the nodes of the graph
+type Kind string
+
+const (
+	Application    Kind = "Application"
+	ApplicationSet Kind = "ApplicationSet"
+	Kustomization  Kind = "Kustomization"
+	Directory      Kind = "Directory"
+)
+
+// Node an Application, an ApplicationSet or a directory (with or without a Kustomization)
+type Node struct {
+	ID   string `json:"id"`
+	Kind Kind   `json:"kind"`
+	// Name of the Application or ApplicationSet, or path of the directory (relative to the base directory)
+	Name string `json:"name"`
+	// File in which the Application or ApplicationSet is defined (relative to the base directory)
+	File string `json:"file,omitempty"`
+}
+
+// Edge a dependency between two nodes: an Application (or ApplicationSet) depends on the directory of its source(s),
+// a Kustomization depends on the directories and the Applications (or ApplicationSets) that it references
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph the dependencies between the Applications, ApplicationSets and Kustomizations of a repository
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build returns the graph of the Applications and ApplicationSets in the `apps` paths, and of the Kustomizations
+// in the `apps` and `components` paths (relative to the base directory).
+// The source paths of the ApplicationSets are resolved with the elements of their `list` generators,
+// sources whose path still contains a `{{...}}` placeholder are ignored.
+func Build(logger *log.Logger, afs afero.Afero, baseDir string, apps, components []string) (*Graph, error) {
+	b := &builder{
+		baseDir: baseDir,
+		nodes:   map[string]Node{},
+		edges:   map[Edge]bool{},
+	}
+	for _, path := range apps {
+		var generateErr error
+		if err := applications.WalkApplications(logger, afs, filepath.Join(baseDir, path), func(path string, app *argocdv1alpha1.Application, appset *argocdv1alpha1.ApplicationSet) {
+			switch {
+			case app != nil:
+				id := b.node(Application, b.rel(path), app.Name)
+				b.sources(id, app.Spec.GetSources())
+			case generateErr == nil:
+				id := b.node(ApplicationSet, b.rel(path), appset.Name)
+				generated, err := applications.GenerateApplications(appset)
+				if err != nil {
+					generateErr = err
+					return
+				}
+				for _, app := range generated {
+					b.sources(id, app.Spec.GetSources())
+				}
+			}
+		}); err != nil {
+			return nil, err
+		}
+		if generateErr != nil {
+			return nil, generateErr
+		}
+	}
+
+	kgraph, err := kustomizations.NewGraph(logger, afs, baseDir, append(apps, components...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range kgraph.Dirs() {
+		from := b.node(Kustomization, "", b.rel(dir))
+		for _, ref := range kgraph.References(dir) {
+			info, err := afs.Stat(ref)
+			if err != nil {
+				// missing references are reported by `check-config`
+				logger.Debug("skipping missing reference", "path", ref)
+				continue
+			}
+			if info.IsDir() {
+				b.edge(from, b.node(Kustomization, "", b.rel(ref)))
+				continue
+			}
+			// Applications and ApplicationSets listed as resources of the Kustomization (app-of-apps)
+			data, err := afs.ReadFile(ref)
+			if err != nil {
+				return nil, err
+			}
+			objs, err := kube.SplitYAML(data)
+			if err != nil {
+				logger.Debug("skipping invalid resource", "path", ref, "err", err)
+				continue
+			}
+			for _, obj := range objs {
+				switch obj.GetKind() {
+				case string(Application):
+					b.edge(from, b.node(Application, b.rel(ref), obj.GetName()))
+				case string(ApplicationSet):
+					b.edge(from, b.node(ApplicationSet, b.rel(ref), obj.GetName()))
+				}
+			}
+		}
+	}
+	return b.graph(), nil
+}
+
+// Dependents returns the subgraph of the nodes which depend (directly or transitively) on the node with the given ID,
+// including this node
+func (g *Graph) Dependents(id string) *Graph {
+	keep := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range g.Edges {
+			if e.To == n && !keep[e.From] {
+				keep[e.From] = true
+				queue = append(queue, e.From)
+			}
+		}
+	}
+	result := &Graph{
+		Nodes: []Node{},
+		Edges: []Edge{},
+	}
+	for _, n := range g.Nodes {
+		if keep[n.ID] {
+			result.Nodes = append(result.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if keep[e.From] && keep[e.To] {
+			result.Edges = append(result.Edges, e)
+		}
+	}
+	return result
+}
+
+// ID returns the ID of the node of the given kind and name. The file (relative to the base directory) in which
+// an Application or ApplicationSet is defined is part of its ID, so that the objects with the same name
+// in different files are different nodes. The file is ignored for the directories.
+func ID(kind Kind, file, name string) string {
+	switch kind {
+	case Application:
+		return "app:" + file + ":" + name
+	case ApplicationSet:
+		return "appset:" + file + ":" + name
+	default:
+		return "dir:" + name
+	}
+}
+
+type builder struct {
+	baseDir string
+	nodes   map[string]Node
+	edges   map[Edge]bool
+}
+
+// adds the node (if needed) and returns its ID. A directory becomes a Kustomization when it is found as such.
+func (b *builder) node(kind Kind, file, name string) string {
+	id := ID(kind, file, name)
+	if existing, found := b.nodes[id]; !found || existing.Kind == Directory {
+		n := Node{
+			ID:   id,
+			Kind: kind,
+			Name: name,
+		}
+		if kind == Application || kind == ApplicationSet {
+			n.File = file
+		}
+		b.nodes[id] = n
+	}
+	return id
+}
+
+func (b *builder) edge(from, to string) {
+	b.edges[Edge{From: from, To: to}] = true
+}
+
+// adds the edges between the given node and the directories of the sources
+func (b *builder) sources(from string, sources argocdv1alpha1.ApplicationSources) {
+	for _, s := range sources {
+		if s.Path == "" || applications.IsTemplated(s.Path) {
+			continue
+		}
+		b.edge(from, b.node(Directory, "", filepath.Clean(s.Path)))
+	}
+}
+
+// returns the path relative to the base directory
+func (b *builder) rel(path string) string {
+	rel, err := filepath.Rel(b.baseDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// returns the graph with the nodes and edges sorted by ID
+func (b *builder) graph() *Graph {
+	g := &Graph{
+		Nodes: make([]Node, 0, len(b.nodes)),
+		Edges: make([]Edge, 0, len(b.edges)),
+	}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	for e := range b.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
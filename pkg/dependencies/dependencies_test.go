@@ -0,0 +1,212 @@
+package dependencies_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/dependencies"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+
+	files := map[string]string{
+		"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- cookie.yaml
+- pasta.yaml`,
+		"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    name: in-cluster
+  project: default
+  source:
+    path: components/cookie`,
+		"/path/to/apps/pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - list:
+      elements:
+      - env: dev
+      - env: prod
+  template:
+    metadata:
+      name: 'pasta-{{env}}'
+    spec:
+      destination:
+        server: https://kubernetes.default.svc
+      project: default
+      source:
+        path: 'components/pasta/{{env}}'`,
+		"/path/to/components/base/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- deployment.yaml`,
+		"/path/to/components/base/deployment.yaml": `kind: Deployment`,
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- ../base`,
+		"/path/to/components/pasta/dev/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- ../../base`,
+	}
+
+	t.Run("applications and applicationsets", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		g, err := dependencies.Build(logger, afs, "/path/to", []string{"apps"}, []string{"components"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []dependencies.Node{
+			{ID: "app:apps/cookie.yaml:cookie", Kind: dependencies.Application, Name: "cookie", File: "apps/cookie.yaml"},
+			{ID: "appset:apps/pasta.yaml:pasta", Kind: dependencies.ApplicationSet, Name: "pasta", File: "apps/pasta.yaml"},
+			{ID: "dir:apps", Kind: dependencies.Kustomization, Name: "apps"},
+			{ID: "dir:components/base", Kind: dependencies.Kustomization, Name: "components/base"},
+			{ID: "dir:components/cookie", Kind: dependencies.Kustomization, Name: "components/cookie"},
+			{ID: "dir:components/pasta/dev", Kind: dependencies.Kustomization, Name: "components/pasta/dev"},
+			{ID: "dir:components/pasta/prod", Kind: dependencies.Directory, Name: "components/pasta/prod"},
+		}, g.Nodes)
+		assert.Equal(t, []dependencies.Edge{
+			{From: "app:apps/cookie.yaml:cookie", To: "dir:components/cookie"},
+			{From: "appset:apps/pasta.yaml:pasta", To: "dir:components/pasta/dev"},
+			{From: "appset:apps/pasta.yaml:pasta", To: "dir:components/pasta/prod"},
+			{From: "dir:apps", To: "app:apps/cookie.yaml:cookie"},
+			{From: "dir:apps", To: "appset:apps/pasta.yaml:pasta"},
+			{From: "dir:components/cookie", To: "dir:components/base"},
+			{From: "dir:components/pasta/dev", To: "dir:components/base"},
+		}, g.Edges)
+
+		t.Run("dependents", func(t *testing.T) {
+			// when
+			d := g.Dependents("dir:components/cookie")
+
+			// then
+			assert.Equal(t, []dependencies.Edge{
+				{From: "app:apps/cookie.yaml:cookie", To: "dir:components/cookie"},
+				{From: "dir:apps", To: "app:apps/cookie.yaml:cookie"},
+			}, d.Edges)
+			assert.Len(t, d.Nodes, 3)
+		})
+	})
+
+	t.Run("applications with the same name in different files", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/member-1/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    name: member-1
+  project: default
+  source:
+    path: components/pasta/dev`,
+		})
+
+		// when
+		g, err := dependencies.Build(logger, afs, "/path/to", []string{"apps"}, []string{"components"})
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, g.Nodes, dependencies.Node{ID: "app:apps/cookie.yaml:cookie", Kind: dependencies.Application, Name: "cookie", File: "apps/cookie.yaml"})
+		assert.Contains(t, g.Nodes, dependencies.Node{ID: "app:apps/member-1/cookie.yaml:cookie", Kind: dependencies.Application, Name: "cookie", File: "apps/member-1/cookie.yaml"})
+		assert.Contains(t, g.Edges, dependencies.Edge{From: "app:apps/cookie.yaml:cookie", To: "dir:components/cookie"})
+		assert.Contains(t, g.Edges, dependencies.Edge{From: "app:apps/member-1/cookie.yaml:cookie", To: "dir:components/pasta/dev"})
+		assert.NotContains(t, g.Edges, dependencies.Edge{From: "app:apps/cookie.yaml:cookie", To: "dir:components/pasta/dev"})
+	})
+}
+
+func TestWrite(t *testing.T) {
+	// given
+	g := &dependencies.Graph{
+		Nodes: []dependencies.Node{
+			{ID: "app:cookie", Kind: dependencies.Application, Name: "cookie"},
+			{ID: "dir:components/cookie", Kind: dependencies.Kustomization, Name: "components/cookie"},
+		},
+		Edges: []dependencies.Edge{
+			{From: "app:cookie", To: "dir:components/cookie"},
+		},
+	}
+
+	t.Run("dot", func(t *testing.T) {
+		// given
+		out := &bytes.Buffer{}
+
+		// when
+		err := g.Write(out, dependencies.DOT)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `digraph dependencies {
+  rankdir=LR;
+  "app:cookie" [label="cookie", shape=box];
+  "dir:components/cookie" [label="components/cookie", shape=folder];
+  "app:cookie" -> "dir:components/cookie";
+}
+`, out.String())
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		// given
+		out := &bytes.Buffer{}
+
+		// when
+		err := g.Write(out, dependencies.Mermaid)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `flowchart LR
+  n0["cookie"]
+  n1[/"components/cookie"/]
+  n0 --> n1
+`, out.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// given
+		out := &bytes.Buffer{}
+
+		// when
+		err := g.Write(out, dependencies.JSON)
+
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+  "nodes": [
+    {"id": "app:cookie", "kind": "Application", "name": "cookie"},
+    {"id": "dir:components/cookie", "kind": "Kustomization", "name": "components/cookie"}
+  ],
+  "edges": [
+    {"from": "app:cookie", "to": "dir:components/cookie"}
+  ]
+}`, out.String())
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		// when
+		err := g.Write(&bytes.Buffer{}, "svg")
+
+		// then
+		require.EqualError(t, err, "unsupported format: 'svg' (expected 'dot', 'mermaid' or 'json')")
+	})
+}
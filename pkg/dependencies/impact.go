@@ -0,0 +1,174 @@
+package dependencies
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// Impact an Application which is affected by a change, with its destination
+type Impact struct {
+	Application string `json:"application"`
+	// ApplicationSet which generates the Application, if any
+	ApplicationSet string `json:"applicationSet,omitempty"`
+	// File in which the Application (or ApplicationSet) is declared (relative to the base directory)
+	File      string `json:"file"`
+	Server    string `json:"server,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Paths of the affected sources of the Application (relative to the base directory)
+	Paths []string `json:"paths"`
+}
+
+// Impacted returns the Applications (including the Applications generated by the ApplicationSets) in the `apps` paths
+// which are affected by a change in the given files (all paths are relative to the base directory):
+// - the Applications whose source is a directory which contains one of the files,
+// - the Applications whose source is a Kustomization which depends (transitively) on one of the files,
+// - the Applications (or ApplicationSets) declared in one of the files.
+// Applications are identified by their name and the file in which they are declared, and the result is sorted by
+// Application name and file.
+func Impacted(logger *log.Logger, afs afero.Afero, baseDir string, apps, components []string, files ...string) ([]Impact, error) {
+	changed := make([]string, len(files))
+	for i, f := range files {
+		changed[i] = filepath.Join(baseDir, f)
+	}
+	kgraph, err := kustomizations.NewGraph(logger, afs, baseDir, append(apps, components...)...)
+	if err != nil {
+		return nil, err
+	}
+	affected := map[string]bool{}
+	for _, dir := range kgraph.Affected(changed...) {
+		affected[dir] = true
+	}
+
+	impacts := map[string]*Impact{}
+	add := func(path string, app *argocdv1alpha1.Application, appset string, all bool) {
+		paths := []string{}
+		for _, s := range app.Spec.GetSources() {
+			if s.Path == "" {
+				continue
+			}
+			p := filepath.Join(baseDir, s.Path)
+			if all || affected[p] || slices.ContainsFunc(changed, func(c string) bool { return kustomizations.Contains(p, c) }) {
+				paths = append(paths, filepath.Clean(s.Path))
+			}
+		}
+		if len(paths) == 0 && !all {
+			return
+		}
+		rpath, _ := filepath.Rel(baseDir, path)
+		key := rpath + "/" + app.Name
+		i, found := impacts[key]
+		if !found {
+			i = &Impact{
+				Application:    app.Name,
+				ApplicationSet: appset,
+				File:           rpath,
+				Server:         app.Spec.Destination.Server,
+				Cluster:        app.Spec.Destination.Name,
+				Namespace:      app.Spec.Destination.Namespace,
+				Paths:          []string{},
+			}
+			impacts[key] = i
+		}
+		i.Paths = appendMissing(i.Paths, paths...)
+	}
+	addAppSet := func(path string, appset *argocdv1alpha1.ApplicationSet, all bool) error {
+		generated, err := applications.GenerateApplications(appset)
+		if err != nil {
+			return err
+		}
+		for _, app := range generated {
+			add(path, app, appset.Name, all)
+		}
+		return nil
+	}
+
+	for _, path := range apps {
+		var generateErr error
+		if err := applications.WalkApplications(logger, afs, filepath.Join(baseDir, path), func(path string, app *argocdv1alpha1.Application, appset *argocdv1alpha1.ApplicationSet) {
+			switch {
+			case app != nil:
+				add(path, app, "", false)
+			case generateErr == nil:
+				generateErr = addAppSet(path, appset, false)
+			}
+		}); err != nil {
+			return nil, err
+		}
+		if generateErr != nil {
+			return nil, generateErr
+		}
+	}
+	// Applications and ApplicationSets which are changed
+	for _, f := range changed {
+		if ext := filepath.Ext(f); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		data, err := afs.ReadFile(f)
+		if err != nil {
+			// deleted file
+			logger.Debug("skipping file", "path", f, "err", err)
+			continue
+		}
+		objs, err := kube.SplitYAML(data)
+		if err != nil {
+			logger.Debug("skipping invalid file", "path", f, "err", err)
+			continue
+		}
+		for _, obj := range objs {
+			data, err := obj.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			switch obj.GetKind() {
+			case string(Application):
+				app := &argocdv1alpha1.Application{}
+				if err := yaml.Unmarshal(data, app); err != nil {
+					return nil, err
+				}
+				add(f, app, "", true)
+			case string(ApplicationSet):
+				appset := &argocdv1alpha1.ApplicationSet{}
+				if err := yaml.Unmarshal(data, appset); err != nil {
+					return nil, err
+				}
+				if err := addAppSet(f, appset, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	result := make([]Impact, 0, len(impacts))
+	for _, i := range impacts {
+		sort.Strings(i.Paths)
+		result = append(result, *i)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Application != result[j].Application {
+			return result[i].Application < result[j].Application
+		}
+		return result[i].File < result[j].File
+	})
+	return result, nil
+}
+
+// appends the values which are not in the slice yet
+func appendMissing(values []string, others ...string) []string {
+	for _, o := range others {
+		if !slices.Contains(values, o) {
+			values = append(values, o)
+		}
+	}
+	return values
+}
@@ -0,0 +1,212 @@
+package dependencies_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/dependencies"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpacted(t *testing.T) {
+
+	files := map[string]string{
+		"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- cookie.yaml
+- pasta.yaml`,
+		"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    name: in-cluster
+  project: default
+  source:
+    path: components/cookie`,
+		"/path/to/apps/pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: pasta
+spec:
+  generators:
+  - list:
+      elements:
+      - env: dev
+      - env: prod
+  template:
+    metadata:
+      name: 'pasta-{{env}}'
+    spec:
+      destination:
+        server: https://kubernetes.default.svc
+      project: default
+      source:
+        path: 'components/pasta/{{env}}'`,
+		"/path/to/components/base/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- deployment.yaml`,
+		"/path/to/components/base/deployment.yaml": `kind: Deployment`,
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- ../base`,
+		"/path/to/components/pasta/dev/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- ../../base`,
+	}
+
+	t.Run("change in base", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		impacts, err := dependencies.Impacted(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, "components/base/deployment.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []dependencies.Impact{
+			{
+				Application: "cookie",
+				File:        "apps/cookie.yaml",
+				Cluster:     "in-cluster",
+				Paths:       []string{"components/cookie"},
+			},
+			{
+				Application:    "pasta-dev",
+				ApplicationSet: "pasta",
+				File:           "apps/pasta.yaml",
+				Server:         "https://kubernetes.default.svc",
+				Paths:          []string{"components/pasta/dev"},
+			},
+		}, impacts)
+	})
+
+	t.Run("change in directory without kustomization", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/components/pasta/prod/deployment.yaml": `kind: Deployment`,
+		})
+
+		// when
+		impacts, err := dependencies.Impacted(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, "components/pasta/prod/deployment.yaml")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, impacts, 1)
+		assert.Equal(t, "pasta-prod", impacts[0].Application)
+	})
+
+	t.Run("change in application", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		impacts, err := dependencies.Impacted(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, "apps/pasta.yaml")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, impacts, 2)
+		assert.Equal(t, "pasta-dev", impacts[0].Application)
+		assert.Equal(t, "pasta-prod", impacts[1].Application)
+	})
+
+	t.Run("applications with the same name in different files", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/member-1/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    name: member-1
+    namespace: cookie
+  project: default
+  source:
+    path: components/cookie`,
+		})
+
+		// when
+		impacts, err := dependencies.Impacted(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, "components/cookie/kustomization.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []dependencies.Impact{
+			{
+				Application: "cookie",
+				File:        "apps/cookie.yaml",
+				Cluster:     "in-cluster",
+				Paths:       []string{"components/cookie"},
+			},
+			{
+				Application: "cookie",
+				File:        "apps/member-1/cookie.yaml",
+				Cluster:     "member-1",
+				Namespace:   "cookie",
+				Paths:       []string{"components/cookie"},
+			},
+		}, impacts)
+	})
+
+	t.Run("templated applicationsets with the same name in different files", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		appset := `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: cookie
+spec:
+  generators:
+  - clusters: {}
+  template:
+    metadata:
+      name: 'cookie-{{name}}'
+    spec:
+      destination:
+        server: '{{server}}'
+      project: default
+      source:
+        path: components/cookie`
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/hosts/cookie.yaml":   appset,
+			"/path/to/apps/members/cookie.yaml": appset,
+		})
+
+		// when
+		impacts, err := dependencies.Impacted(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, "components/cookie/kustomization.yaml")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, impacts, 3)
+		assert.Equal(t, "cookie-{{name}}", impacts[1].Application)
+		assert.Equal(t, "apps/hosts/cookie.yaml", impacts[1].File)
+		assert.Equal(t, "cookie-{{name}}", impacts[2].Application)
+		assert.Equal(t, "apps/members/cookie.yaml", impacts[2].File)
+	})
+
+	t.Run("no impact", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		impacts, err := dependencies.Impacted(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, "README.md")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, impacts)
+	})
+}
@@ -0,0 +1,104 @@
+package dependencies
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Formats in which the graph can be written
+const (
+	DOT     = "dot"
+	Mermaid = "mermaid"
+	JSON    = "json"
+)
+
+// Write writes the graph in the given format (`dot`, `mermaid` or `json`)
+func (g *Graph) Write(out io.Writer, format string) error {
+	switch format {
+	case DOT:
+		return g.WriteDOT(out)
+	case Mermaid:
+		return g.WriteMermaid(out)
+	case JSON:
+		return g.WriteJSON(out)
+	default:
+		return fmt.Errorf("unsupported format: '%s' (expected '%s', '%s' or '%s')", format, DOT, Mermaid, JSON)
+	}
+}
+
+// shapes of the nodes in the DOT format, by kind
+var dotShapes = map[Kind]string{
+	Application:    "box",
+	ApplicationSet: "box3d",
+	Kustomization:  "folder",
+	Directory:      "folder",
+}
+
+// WriteDOT writes the graph in the Graphviz DOT format
+func (g *Graph) WriteDOT(out io.Writer) error {
+	w := &writer{out: out}
+	w.printf("digraph dependencies {\n")
+	w.printf("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		style := ""
+		if n.Kind == Directory {
+			// directory without Kustomization
+			style = `, style=dashed`
+		}
+		w.printf("  %s [label=%s, shape=%s%s];\n", strconv.Quote(n.ID), strconv.Quote(n.Name), dotShapes[n.Kind], style)
+	}
+	for _, e := range g.Edges {
+		w.printf("  %s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+	w.printf("}\n")
+	return w.err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart
+func (g *Graph) WriteMermaid(out io.Writer) error {
+	w := &writer{out: out}
+	w.printf("flowchart LR\n")
+	// Mermaid IDs can't contain special characters
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		label := strconv.Quote(n.Name)
+		switch n.Kind {
+		case Application:
+			w.printf("  %s[%s]\n", ids[n.ID], label)
+		case ApplicationSet:
+			w.printf("  %s[[%s]]\n", ids[n.ID], label)
+		default:
+			w.printf("  %s[/%s/]\n", ids[n.ID], label)
+		}
+	}
+	for _, e := range g.Edges {
+		w.printf("  %s --> %s\n", ids[e.From], ids[e.To])
+	}
+	return w.err
+}
+
+// WriteJSON writes the nodes and edges of the graph in JSON
+func (g *Graph) WriteJSON(out io.Writer) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// writer which keeps the first error, to avoid checking the error of each line
+type writer struct {
+	out io.Writer
+	err error
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, args...)
+}
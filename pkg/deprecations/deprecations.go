@@ -0,0 +1,89 @@
+package deprecations
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed apis.yaml
+var apisData []byte
+
+// Version a Kubernetes minor version (eg: `1.32`)
+type Version struct {
+	Major int
+	Minor int
+}
+
+// ParseVersion parses a Kubernetes version in the `[v]<major>.<minor>[.<patch>]` format (the patch version is ignored)
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid Kubernetes version: '%s'", s)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid Kubernetes version: '%s'", s)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid Kubernetes version: '%s'", s)
+	}
+	return Version{
+		Major: major,
+		Minor: minor,
+	}, nil
+}
+
+// AtLeast returns true if the version is the same as or more recent than the other version
+func (v Version) AtLeast(other Version) bool {
+	return v.Major > other.Major || (v.Major == other.Major && v.Minor >= other.Minor)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// UnmarshalJSON parses the version from a JSON string
+func (v *Version) UnmarshalJSON(data []byte) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return err
+	}
+	*v, err = ParseVersion(s)
+	return err
+}
+
+// Deprecation a deprecated API version of some kinds, with the Kubernetes versions in which it was deprecated and removed,
+// and its replacement (if any)
+type Deprecation struct {
+	APIVersion   string   `json:"apiVersion"`
+	Kinds        []string `json:"kinds"`
+	DeprecatedIn Version  `json:"deprecatedIn"`
+	RemovedIn    Version  `json:"removedIn"`
+	Replacement  string   `json:"replacement,omitempty"`
+}
+
+// the deprecations, by API version and kind
+var deprecations = map[string]Deprecation{}
+
+func init() {
+	all := []Deprecation{}
+	if err := yaml.UnmarshalStrict(apisData, &all); err != nil {
+		panic(fmt.Sprintf("invalid table of deprecated APIs: %s", err))
+	}
+	for _, d := range all {
+		for _, k := range d.Kinds {
+			deprecations[d.APIVersion+"/"+k] = d
+		}
+	}
+}
+
+// Lookup returns the deprecation of the given API version and kind, if any
+func Lookup(apiVersion, kind string) (Deprecation, bool) {
+	d, found := deprecations[apiVersion+"/"+kind]
+	return d, found
+}
@@ -0,0 +1,58 @@
+package deprecations_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/deprecations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		for s, expected := range map[string]deprecations.Version{
+			"1.32":     {Major: 1, Minor: 32},
+			"v1.25":    {Major: 1, Minor: 25},
+			"v1.29.10": {Major: 1, Minor: 29},
+		} {
+			t.Run(s, func(t *testing.T) {
+				v, err := deprecations.ParseVersion(s)
+				require.NoError(t, err)
+				assert.Equal(t, expected, v)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{"", "1", "1.x", "one.two"} {
+			t.Run(s, func(t *testing.T) {
+				_, err := deprecations.ParseVersion(s)
+				require.EqualError(t, err, "invalid Kubernetes version: '"+s+"'")
+			})
+		}
+	})
+}
+
+func TestLookup(t *testing.T) {
+
+	t.Run("removed API", func(t *testing.T) {
+		// when
+		d, found := deprecations.Lookup("batch/v1beta1", "CronJob")
+
+		// then
+		require.True(t, found)
+		assert.Equal(t, "1.21", d.DeprecatedIn.String())
+		assert.Equal(t, "1.25", d.RemovedIn.String())
+		assert.Equal(t, "batch/v1", d.Replacement)
+	})
+
+	t.Run("current API", func(t *testing.T) {
+		// when
+		_, found := deprecations.Lookup("batch/v1", "CronJob")
+
+		// then
+		require.False(t, found)
+	})
+}
@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Status of an Application or an object between the base and the head revisions
+type Status string
+
+const (
+	Added     Status = "added"
+	Removed   Status = "removed"
+	Modified  Status = "modified"
+	Unchanged Status = "unchanged"
+)
+
+// ApplicationDiff the differences between the manifests rendered by an Application at the base and the head revisions
+type ApplicationDiff struct {
+	Application string `json:"application"`
+	// File in which the Application is defined (relative to the base directory), if known
+	File   string `json:"file,omitempty"`
+	Status Status `json:"status"`
+	// Objects which are added, removed or modified, sorted by ID
+	Objects []ObjectDiff `json:"objects"`
+}
+
+// Count returns the number of objects with the given status
+func (d ApplicationDiff) Count(status Status) int {
+	count := 0
+	for _, o := range d.Objects {
+		if o.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// ObjectDiff the differences of a rendered object between the base and the head revisions
+type ObjectDiff struct {
+	// ID of the object (eg: `Deployment/namespace/name`, or `ClusterRole/name` for a cluster-scoped object)
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	// Diff in the unified format
+	Diff string `json:"diff"`
+}
+
+// Objects compares the objects rendered at the base and the head revisions, matching them by group, kind, namespace and name.
+// Unchanged objects are not included in the result, which is sorted by ID.
+func Objects(base, head []*unstructured.Unstructured) ([]ObjectDiff, error) {
+	baseObjs := index(base)
+	headObjs := index(head)
+	keys := make([]string, 0, len(baseObjs)+len(headObjs))
+	for k := range baseObjs {
+		keys = append(keys, k)
+	}
+	for k := range headObjs {
+		if _, found := baseObjs[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	diffs := make([]ObjectDiff, 0, len(keys))
+	for _, k := range keys {
+		b, h := baseObjs[k], headObjs[k]
+		from, err := toYAML(b)
+		if err != nil {
+			return nil, err
+		}
+		to, err := toYAML(h)
+		if err != nil {
+			return nil, err
+		}
+		if from == to {
+			continue
+		}
+		status := Modified
+		obj := h
+		switch {
+		case b == nil:
+			status = Added
+		case h == nil:
+			status = Removed
+			obj = b
+		}
+		id := ID(obj)
+		d, err := unified("a/"+id, "b/"+id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, ObjectDiff{
+			ID:     id,
+			Status: status,
+			Diff:   d,
+		})
+	}
+	sortByID(diffs)
+	return diffs, nil
+}
+
+func sortByID(diffs []ObjectDiff) {
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].ID < diffs[j].ID
+	})
+}
+
+// returns the differences between the given texts in the unified format
+func unified(fromFile, toFile, from, to string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines(from),
+		B:        lines(to),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// ID returns the ID of the given object (eg: `Deployment/namespace/name`, or `ClusterRole/name` for a cluster-scoped object)
+func ID(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// indexes the objects by group, kind, namespace and name
+func index(objs []*unstructured.Unstructured) map[string]*unstructured.Unstructured {
+	result := make(map[string]*unstructured.Unstructured, len(objs))
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		result[fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName())] = obj
+	}
+	return result
+}
+
+// splits the given text into lines, keeping the line endings
+// (unlike `difflib.SplitLines` which adds an extra empty line at the end)
+func lines(s string) []string {
+	result := strings.SplitAfter(s, "\n")
+	if result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+func toYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := yaml.Marshal(obj.Object)
+	return string(data), err
+}
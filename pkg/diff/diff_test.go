@@ -0,0 +1,93 @@
+package diff_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjects(t *testing.T) {
+	// given
+	base := []*unstructured.Unstructured{
+		test.NewObject("apps/v1", "Deployment", "cookie", "cookie", map[string]interface{}{"replicas": int64(1)}),
+		test.NewObject("v1", "ConfigMap", "cookie", "cookie", map[string]interface{}{"flavor": "chocolate"}),
+		test.NewObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "cookie", nil),
+	}
+	head := []*unstructured.Unstructured{
+		test.NewObject("apps/v1", "Deployment", "cookie", "cookie", map[string]interface{}{"replicas": int64(2)}),
+		test.NewObject("v1", "ConfigMap", "cookie", "cookie", map[string]interface{}{"flavor": "chocolate"}),
+		test.NewObject("v1", "Service", "cookie", "cookie", nil),
+	}
+
+	// when
+	diffs, err := diff.Objects(base, head)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	assert.Equal(t, "ClusterRole/cookie", diffs[0].ID)
+	assert.Equal(t, diff.Removed, diffs[0].Status)
+	assert.Contains(t, diffs[0].Diff, "-kind: ClusterRole\n")
+	assert.Equal(t, "Deployment/cookie/cookie", diffs[1].ID)
+	assert.Equal(t, diff.Modified, diffs[1].Status)
+	assert.Equal(t, `--- a/Deployment/cookie/cookie
++++ b/Deployment/cookie/cookie
+@@ -4,4 +4,4 @@
+   name: cookie
+   namespace: cookie
+ spec:
+-  replicas: 1
++  replicas: 2
+`, diffs[1].Diff)
+	assert.Equal(t, "Service/cookie/cookie", diffs[2].ID)
+	assert.Equal(t, diff.Added, diffs[2].Status)
+	assert.Contains(t, diffs[2].Diff, "+kind: Service\n")
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	// given
+	diffs := []diff.ApplicationDiff{
+		{
+			Application: "cookie",
+			File:        "apps/cookie.yaml",
+			Status:      diff.Modified,
+			Objects: []diff.ObjectDiff{
+				{ID: "Deployment/cookie/cookie", Status: diff.Modified, Diff: "-  replicas: 1\n+  replicas: 2\n"},
+			},
+		},
+		{
+			Application: "pasta",
+			Status:      diff.Unchanged,
+			Objects:     []diff.ObjectDiff{},
+		},
+	}
+	out := &bytes.Buffer{}
+
+	// when
+	err := diff.WriteMarkdown(out, "main", "HEAD", diffs)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "### Rendered manifests (`main`...`HEAD`)\n"+
+		"\n"+
+		"| Application | Status | Added | Removed | Modified |\n"+
+		"| --- | --- | ---: | ---: | ---: |\n"+
+		"| `cookie` in apps/cookie.yaml | modified | 0 | 0 | 1 |\n"+
+		"| `pasta` | unchanged | 0 | 0 | 0 |\n"+
+		"\n"+
+		"<details>\n"+
+		"<summary><code>cookie</code> in apps/cookie.yaml (1 object(s) changed)</summary>\n"+
+		"\n"+
+		"```diff\n"+
+		"-  replicas: 1\n"+
+		"+  replicas: 2\n"+
+		"```\n"+
+		"\n"+
+		"</details>\n", out.String())
+}
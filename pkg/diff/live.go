@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	gitopsdiff "github.com/argoproj/gitops-engine/pkg/diff"
+	"github.com/charmbracelet/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Live compares the given objects rendered by the Application with their live state in the cluster.
+// As Argo CD does, the live objects are normalized before the comparison:
+// - the fields populated by the server (status, managedFields, resourceVersion, etc.) are removed,
+// - the fields which are not set in the rendered objects (eg: defaulted fields) are ignored,
+// - the fields matching the `ignoreDifferences` rules of the Application are ignored.
+// Rendered objects without a namespace are looked up in the destination namespace of the Application,
+// unless they are cluster-scoped. Objects which exist in the cluster but are not rendered anymore are not reported.
+// Unchanged objects are not included in the result, which is sorted by ID.
+func Live(ctx context.Context, logger *log.Logger, cl runtimeclient.Client, app *argocdv1alpha1.Application, objs []*unstructured.Unstructured) ([]ObjectDiff, error) {
+	normalizer, err := newIgnoreNormalizer(logger, app.Spec.IgnoreDifferences)
+	if err != nil {
+		return nil, err
+	}
+	diffs := []ObjectDiff{}
+	for _, obj := range objs {
+		obj = obj.DeepCopy()
+		if obj.GetNamespace() == "" {
+			namespaced, err := cl.IsObjectNamespaced(obj)
+			if err != nil {
+				return nil, err
+			}
+			if namespaced {
+				obj.SetNamespace(app.Spec.Destination.Namespace)
+			}
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := cl.Get(ctx, runtimeclient.ObjectKeyFromObject(obj), live); apierrors.IsNotFound(err) {
+			logger.Debug("object not found in the cluster", "id", ID(obj))
+			live = nil
+		} else if err != nil {
+			return nil, err
+		}
+		result, err := gitopsdiff.Diff(obj, live, gitopsdiff.WithNormalizer(normalizer))
+		if err != nil {
+			return nil, err
+		}
+		from, err := normalizedYAML(result.NormalizedLive)
+		if err != nil {
+			return nil, err
+		}
+		to, err := normalizedYAML(result.PredictedLive)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Modified || from == to {
+			continue
+		}
+		status := Modified
+		if live == nil {
+			status = Added
+		}
+		id := ID(obj)
+		d, err := unified("live/"+id, "local/"+id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, ObjectDiff{
+			ID:     id,
+			Status: status,
+			Diff:   d,
+		})
+	}
+	sortByID(diffs)
+	return diffs, nil
+}
+
+// annotations which are set by the clients when applying the objects
+var clientAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// converts the given JSON object into YAML, once the fields populated by the server have been removed
+func normalizedYAML(data []byte) (string, error) {
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", err
+	}
+	if obj == nil {
+		// object does not exist
+		return "", nil
+	}
+	unstructured.RemoveNestedField(obj, "status")
+	for _, f := range []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(obj, "metadata", f)
+	}
+	for _, a := range clientAnnotations {
+		unstructured.RemoveNestedField(obj, "metadata", "annotations", a)
+	}
+	if annotations, found, _ := unstructured.NestedMap(obj, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj, "metadata", "annotations")
+	}
+	result, err := yaml.Marshal(obj)
+	return string(result), err
+}
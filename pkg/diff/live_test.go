@@ -0,0 +1,171 @@
+package diff_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestLive(t *testing.T) {
+
+	// given
+	ctx := context.TODO()
+	logger := log.New(os.Stdout)
+	app := &argocdv1alpha1.Application{
+		Spec: argocdv1alpha1.ApplicationSpec{
+			Destination: argocdv1alpha1.ApplicationDestination{
+				Namespace: "cookie",
+			},
+			IgnoreDifferences: []argocdv1alpha1.ResourceIgnoreDifferences{
+				{
+					Group:        "apps",
+					Kind:         "Deployment",
+					JSONPointers: []string{"/spec/replicas"},
+				},
+				{
+					Kind:              "ConfigMap",
+					Name:              "cookie-generated",
+					JQPathExpressions: []string{".data.timestamp"},
+				},
+			},
+		},
+	}
+	// objects in the cluster, with fields populated by the server or by controllers
+	live := []*unstructured.Unstructured{
+		newUnstructured(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: cookie
+  resourceVersion: "123"
+  uid: 5f1b3b5e-1b3b-4b3b-8b3b-5f1b3b5e1b3b
+  managedFields:
+  - manager: argocd-controller
+    operation: Apply
+spec:
+  replicas: 5
+  progressDeadlineSeconds: 600
+  selector:
+    matchLabels:
+      app: cookie
+  template:
+    metadata:
+      labels:
+        app: cookie
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/cookie/cookie:v1.0.0
+        imagePullPolicy: IfNotPresent
+status:
+  replicas: 5
+`),
+		newUnstructured(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+  namespace: cookie
+data:
+  flavor: chocolate
+`),
+		newUnstructured(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie-generated
+  namespace: cookie
+data:
+  timestamp: "2024-01-01T00:00:00Z"
+`),
+	}
+	// rendered objects
+	local := []*unstructured.Unstructured{
+		newUnstructured(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: cookie
+  template:
+    metadata:
+      labels:
+        app: cookie
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/cookie/cookie:v1.0.0
+`),
+		newUnstructured(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: vanilla
+`),
+		newUnstructured(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie-generated
+data:
+  timestamp: "2024-02-02T00:00:00Z"
+`),
+		newUnstructured(t, `apiVersion: v1
+kind: Service
+metadata:
+  name: cookie
+spec:
+  ports:
+  - port: 8080
+`),
+	}
+	builder := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme))
+	for _, obj := range live {
+		builder = builder.WithObjects(obj)
+	}
+	cl := builder.Build()
+
+	// when
+	diffs, err := diff.Live(ctx, logger, cl, app, local)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, "ConfigMap/cookie/cookie", diffs[0].ID)
+	assert.Equal(t, diff.Modified, diffs[0].Status)
+	assert.Equal(t, `--- live/ConfigMap/cookie/cookie
++++ local/ConfigMap/cookie/cookie
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  flavor: chocolate
++  flavor: vanilla
+ kind: ConfigMap
+ metadata:
+   name: cookie
+`, diffs[0].Diff)
+	assert.Equal(t, "Service/cookie/cookie", diffs[1].ID)
+	assert.Equal(t, diff.Added, diffs[1].Status)
+}
+
+func newUnstructured(t *testing.T, data string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	err := yaml.Unmarshal([]byte(data), &obj.Object)
+	require.NoError(t, err)
+	return obj
+}
@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/itchyny/gojq"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maximum duration of the evaluation of a JQ path expression (same default as Argo CD)
+const jqTimeout = time.Second
+
+// ignoreNormalizer removes the fields matching the `ignoreDifferences` rules of an Application,
+// as Argo CD does (see https://argo-cd.readthedocs.io/en/stable/user-guide/diffing/)
+type ignoreNormalizer struct {
+	logger *log.Logger
+	rules  []ignoreRule
+}
+
+// ignoreRule the JSON pointers and JQ path expressions to remove from the matching objects
+type ignoreRule struct {
+	argocdv1alpha1.ResourceIgnoreDifferences
+	pointers    []jsonpatch.Patch
+	expressions []*gojq.Code
+}
+
+func newIgnoreNormalizer(logger *log.Logger, ignore []argocdv1alpha1.ResourceIgnoreDifferences) (*ignoreNormalizer, error) {
+	n := &ignoreNormalizer{
+		logger: logger,
+		rules:  make([]ignoreRule, 0, len(ignore)),
+	}
+	for _, i := range ignore {
+		if len(i.ManagedFieldsManagers) > 0 {
+			logger.Warn("ignoring differences by managed fields managers is not supported", "group", i.Group, "kind", i.Kind, "name", i.Name)
+		}
+		r := ignoreRule{
+			ResourceIgnoreDifferences: i,
+		}
+		for _, p := range i.JSONPointers {
+			data, err := json.Marshal([]map[string]string{{"op": "remove", "path": p}})
+			if err != nil {
+				return nil, err
+			}
+			patch, err := jsonpatch.DecodePatch(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON pointer in ignoreDifferences: '%s': %w", p, err)
+			}
+			r.pointers = append(r.pointers, patch)
+		}
+		for _, e := range i.JQPathExpressions {
+			query, err := gojq.Parse(fmt.Sprintf("del(%s)", e))
+			if err != nil {
+				return nil, fmt.Errorf("invalid JQ path expression in ignoreDifferences: '%s': %w", e, err)
+			}
+			code, err := gojq.Compile(query)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JQ path expression in ignoreDifferences: '%s': %w", e, err)
+			}
+			r.expressions = append(r.expressions, code)
+		}
+		n.rules = append(n.rules, r)
+	}
+	return n, nil
+}
+
+// Normalize removes the ignored fields from the given object.
+// Fields which cannot be removed (eg: because they do not exist) are skipped.
+func (n *ignoreNormalizer) Normalize(un *unstructured.Unstructured) error {
+	if un == nil {
+		return nil
+	}
+	gk := un.GroupVersionKind().GroupKind()
+	for _, r := range n.rules {
+		if !match(r.Group, gk.Group) || !match(r.Kind, gk.Kind) ||
+			(r.Name != "" && r.Name != un.GetName()) ||
+			(r.Namespace != "" && r.Namespace != un.GetNamespace()) {
+			continue
+		}
+		for _, p := range r.pointers {
+			data, err := json.Marshal(un.Object)
+			if err != nil {
+				return err
+			}
+			if data, err = p.Apply(data); err != nil {
+				n.logger.Debug("skipping JSON pointer", "id", ID(un), "err", err)
+				continue
+			}
+			if err := un.UnmarshalJSON(data); err != nil {
+				return err
+			}
+		}
+		for _, e := range r.expressions {
+			obj, err := runJQ(e, un.Object)
+			if err != nil {
+				n.logger.Debug("skipping JQ path expression", "id", ID(un), "err", err)
+				continue
+			}
+			un.Object = obj
+		}
+	}
+	return nil
+}
+
+// returns true if the value matches the pattern of a rule (an empty pattern matches any value)
+func match(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+func runJQ(code *gojq.Code, obj map[string]interface{}) (map[string]interface{}, error) {
+	// gojq expects values decoded from JSON (eg: no int64)
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	input := map[string]interface{}{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), jqTimeout)
+	defer cancel()
+	iter := code.RunWithContext(ctx, input)
+	v, ok := iter.Next()
+	if !ok {
+		return nil, fmt.Errorf("JQ path expression did not return any data")
+	}
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	result, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JQ path expression did not return an object")
+	}
+	// convert the numbers back into the types used by unstructured objects
+	if data, err = json.Marshal(result); err != nil {
+		return nil, err
+	}
+	output := &unstructured.Unstructured{}
+	if err := output.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return output.Object, nil
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes the differences of each Application in the unified format
+func WriteText(out io.Writer, diffs []ApplicationDiff) error {
+	w := &writer{out: out}
+	if len(diffs) == 0 {
+		w.printf("no Application is affected\n")
+		return w.err
+	}
+	for i, d := range diffs {
+		if i > 0 {
+			w.printf("\n")
+		}
+		w.printf("Application '%s'%s (%s)\n", d.Application, d.location(), d.Status)
+		for _, o := range d.Objects {
+			w.printf("%s", o.Diff)
+		}
+	}
+	return w.err
+}
+
+// WriteMarkdown writes a summary of the differences of each Application in Markdown, with the diffs
+// in collapsible sections (eg: to post a comment on a pull request)
+func WriteMarkdown(out io.Writer, base, head string, diffs []ApplicationDiff) error {
+	w := &writer{out: out}
+	w.printf("### Rendered manifests (`%s`...`%s`)\n\n", base, head)
+	if len(diffs) == 0 {
+		w.printf("No Application is affected.\n")
+		return w.err
+	}
+	w.printf("| Application | Status | Added | Removed | Modified |\n")
+	w.printf("| --- | --- | ---: | ---: | ---: |\n")
+	for _, d := range diffs {
+		w.printf("| `%s`%s | %s | %d | %d | %d |\n", d.Application, d.location(), d.Status, d.Count(Added), d.Count(Removed), d.Count(Modified))
+	}
+	for _, d := range diffs {
+		if len(d.Objects) == 0 {
+			continue
+		}
+		w.printf("\n<details>\n<summary><code>%s</code>%s (%d object(s) changed)</summary>\n\n", d.Application, d.location(), len(d.Objects))
+		w.printf("```diff\n")
+		for _, o := range d.Objects {
+			// prevent the diff from closing the code block
+			w.printf("%s", strings.ReplaceAll(o.Diff, "```", "` ` `"))
+		}
+		w.printf("```\n\n</details>\n")
+	}
+	return w.err
+}
+
+// returns the file in which the Application is defined, to tell apart the Applications with the same name
+func (d ApplicationDiff) location() string {
+	if d.File == "" {
+		return ""
+	}
+	return " in " + d.File
+}
+
+type writer struct {
+	out io.Writer
+	err error
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, args...)
+}
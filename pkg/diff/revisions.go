@@ -0,0 +1,208 @@
+package diff
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/dependencies"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/gitrepo"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Revisions compares the manifests rendered at the `base` and the `head` revisions of the local Git repository
+// by the Applications (including the Applications generated by the ApplicationSets) in the `apps` paths
+// which are affected by the changes between the two revisions (see `dependencies.Impacted`).
+// Both revisions are checked out in separate in-memory filesystems, so the worktree is left untouched.
+// As with `git diff base...head`, the changes are computed from the merge-base of the two revisions.
+// The `apps` and `components` paths are relative to the base directory. The result is sorted by Application name,
+// then by file, since Applications with the same name can be defined in different files.
+func Revisions(logger *log.Logger, baseDir, base, head string, apps, components []string) ([]ApplicationDiff, error) {
+	// the in-memory filesystems contain the files at their absolute path
+	baseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := gitrepo.ChangedFilesBetween(logger, baseDir, base, head)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(changed))
+	for _, f := range changed {
+		rel, err := filepath.Rel(baseDir, f)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			// file outside of the base directory
+			continue
+		}
+		files = append(files, rel)
+	}
+	logger.Info("🔎 files changed", "base", base, "head", head, "count", len(files))
+
+	baseRev, err := newRevision(logger, baseDir, base, apps, components, files)
+	if err != nil {
+		return nil, err
+	}
+	headRev, err := newRevision(logger, baseDir, head, apps, components, files)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[appKey]bool{}
+	for k := range baseRev.impacted {
+		keys[k] = true
+	}
+	for k := range headRev.impacted {
+		keys[k] = true
+	}
+
+	diffs := make([]ApplicationDiff, 0, len(keys))
+	for k := range keys {
+		logger.Debug("👀 comparing Application", "name", k.name, "file", k.file)
+		baseObjs, err := baseRev.render(k)
+		if err != nil {
+			return nil, err
+		}
+		headObjs, err := headRev.render(k)
+		if err != nil {
+			return nil, err
+		}
+		objs, err := Objects(baseObjs, headObjs)
+		if err != nil {
+			return nil, err
+		}
+		status := Modified
+		switch {
+		case baseRev.apps[k] == nil:
+			status = Added
+		case headRev.apps[k] == nil:
+			status = Removed
+		case len(objs) == 0:
+			status = Unchanged
+		}
+		diffs = append(diffs, ApplicationDiff{
+			Application: k.name,
+			File:        k.file,
+			Status:      status,
+			Objects:     objs,
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Application != diffs[j].Application {
+			return diffs[i].Application < diffs[j].Application
+		}
+		return diffs[i].File < diffs[j].File
+	})
+	return diffs, nil
+}
+
+// appKey identifies an Application by its name and the file in which it is defined (relative to the base directory)
+type appKey struct {
+	file string
+	name string
+}
+
+// revision the contents of the repository at a given revision
+type revision struct {
+	logger   *log.Logger
+	name     string
+	afs      afero.Afero
+	baseDir  string
+	impacted map[appKey]bool
+	// Applications (including the Applications generated by the ApplicationSets), by file and name
+	apps map[appKey]*argocdv1alpha1.Application
+	// in-memory filesystem used to render the Applications, created on the first rendering
+	fsys kfsys.FileSystem
+}
+
+func newRevision(logger *log.Logger, baseDir, name string, apps, components []string, files []string) (*revision, error) {
+	afs, err := gitrepo.Checkout(logger, baseDir, name)
+	if err != nil {
+		return nil, err
+	}
+	r := &revision{
+		logger:   logger,
+		name:     name,
+		afs:      afs,
+		baseDir:  baseDir,
+		impacted: map[appKey]bool{},
+		apps:     map[appKey]*argocdv1alpha1.Application{},
+	}
+	// paths which do not exist at this revision are ignored
+	existing := func(paths []string) []string {
+		result := make([]string, 0, len(paths))
+		for _, p := range paths {
+			if exists, _ := afs.DirExists(filepath.Join(baseDir, p)); exists {
+				result = append(result, p)
+			}
+		}
+		return result
+	}
+	apps, components = existing(apps), existing(components)
+	impacts, err := dependencies.Impacted(logger, afs, baseDir, apps, components, files...)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range impacts {
+		r.impacted[appKey{file: i.File, name: i.Application}] = true
+	}
+	for _, path := range apps {
+		var generateErr error
+		if err := applications.WalkApplications(logger, afs, filepath.Join(baseDir, path), func(path string, app *argocdv1alpha1.Application, appset *argocdv1alpha1.ApplicationSet) {
+			rpath, _ := filepath.Rel(baseDir, path)
+			switch {
+			case app != nil:
+				r.apps[appKey{file: rpath, name: app.Name}] = app
+			case generateErr == nil:
+				generated, err := applications.GenerateApplications(appset)
+				if err != nil {
+					generateErr = err
+					return
+				}
+				for _, app := range generated {
+					r.apps[appKey{file: rpath, name: app.Name}] = app
+				}
+			}
+		}); err != nil {
+			return nil, err
+		}
+		if generateErr != nil {
+			return nil, generateErr
+		}
+	}
+	return r, nil
+}
+
+// renders the manifests of the Application with the given key, or returns `nil` if there is no such Application
+// or if the path of one of its sources contains a `{{...}}` placeholder
+func (r *revision) render(k appKey) ([]*unstructured.Unstructured, error) {
+	app, found := r.apps[k]
+	if !found {
+		return nil, nil
+	}
+	for _, source := range app.Spec.GetSources() {
+		if applications.IsTemplated(source.Path) {
+			r.logger.Debug("skipping rendering of templated Application", "revision", r.name, "name", k.name, "file", k.file)
+			return nil, nil
+		}
+	}
+	if r.fsys == nil {
+		fsys, err := validation.NewInMemoryFS(r.logger, r.afs, r.baseDir)
+		if err != nil {
+			return nil, err
+		}
+		r.fsys = fsys
+	}
+	objs, err := render.Application(r.logger, r.fsys, r.baseDir, app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render Application '%s' in %s at revision '%s': %w", k.name, k.file, r.name, err)
+	}
+	return objs, nil
+}
@@ -0,0 +1,178 @@
+package diff_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevisions(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	dir, repo := test.NewRepository(t)
+	base := test.Commit(t, repo, dir, map[string]string{
+		"apps/kustomization.yaml": `kind: Kustomization
+resources:
+- cookie.yaml
+- pasta.yaml
+`,
+		"apps/cookie.yaml": newApplication("cookie", "components/cookie"),
+		"apps/pasta.yaml":  newApplication("pasta", "components/pasta"),
+		"components/cookie/kustomization.yaml": `kind: Kustomization
+namespace: cookie
+resources:
+- configmap.yaml
+`,
+		"components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: chocolate
+`,
+		"components/pasta/kustomization.yaml": `kind: Kustomization
+namespace: pasta
+resources:
+- configmap.yaml
+`,
+		"components/pasta/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta
+data:
+  shape: penne
+`,
+	})
+	head := test.Commit(t, repo, dir, map[string]string{
+		"apps/kustomization.yaml": `kind: Kustomization
+resources:
+- cookie.yaml
+- pasta.yaml
+- pizza.yaml
+`,
+		"apps/pizza.yaml": newApplication("pizza", "components/pizza"),
+		"components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: vanilla
+`,
+		"components/pizza/kustomization.yaml": `kind: Kustomization
+namespace: pizza
+resources:
+- configmap.yaml
+`,
+		"components/pizza/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pizza
+data:
+  topping: mushrooms
+`,
+	})
+	// uncommitted change, which is ignored
+	err := os.WriteFile(filepath.Join(dir, "components/pasta/configmap.yaml"), []byte("invalid"), 0600)
+	require.NoError(t, err)
+
+	// when
+	diffs, err := diff.Revisions(logger, dir, base, head, []string{"apps"}, []string{"components"})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, "cookie", diffs[0].Application)
+	assert.Equal(t, "apps/cookie.yaml", diffs[0].File)
+	assert.Equal(t, diff.Modified, diffs[0].Status)
+	require.Len(t, diffs[0].Objects, 1)
+	assert.Equal(t, "ConfigMap/cookie/cookie", diffs[0].Objects[0].ID)
+	assert.Equal(t, diff.Modified, diffs[0].Objects[0].Status)
+	assert.Contains(t, diffs[0].Objects[0].Diff, "-  flavor: chocolate\n+  flavor: vanilla\n")
+	assert.Equal(t, "pizza", diffs[1].Application)
+	assert.Equal(t, "apps/pizza.yaml", diffs[1].File)
+	assert.Equal(t, diff.Added, diffs[1].Status)
+	require.Len(t, diffs[1].Objects, 1)
+	assert.Equal(t, "ConfigMap/pizza/pizza", diffs[1].Objects[0].ID)
+	assert.Equal(t, diff.Added, diffs[1].Objects[0].Status)
+
+	t.Run("applications with the same name in different files", func(t *testing.T) {
+		// given
+		dir, repo := test.NewRepository(t)
+		base := test.Commit(t, repo, dir, map[string]string{
+			"apps/cookie.yaml":          newApplication("cookie", "components/cookie"),
+			"apps/member-1/cookie.yaml": newApplication("cookie", "components/member-1/cookie"),
+			"components/cookie/kustomization.yaml": `kind: Kustomization
+resources:
+- configmap.yaml
+`,
+			"components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: chocolate
+`,
+			"components/member-1/cookie/kustomization.yaml": `kind: Kustomization
+namespace: member-1
+resources:
+- ../../cookie
+`,
+		})
+		head := test.Commit(t, repo, dir, map[string]string{
+			"components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  flavor: vanilla
+`,
+		})
+
+		// when
+		diffs, err := diff.Revisions(logger, dir, base, head, []string{"apps"}, []string{"components"})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, diffs, 2)
+		assert.Equal(t, "cookie", diffs[0].Application)
+		assert.Equal(t, "apps/cookie.yaml", diffs[0].File)
+		assert.Equal(t, diff.Modified, diffs[0].Status)
+		require.Len(t, diffs[0].Objects, 1)
+		assert.Equal(t, "ConfigMap/cookie", diffs[0].Objects[0].ID)
+		assert.Equal(t, "cookie", diffs[1].Application)
+		assert.Equal(t, "apps/member-1/cookie.yaml", diffs[1].File)
+		assert.Equal(t, diff.Modified, diffs[1].Status)
+		require.Len(t, diffs[1].Objects, 1)
+		assert.Equal(t, "ConfigMap/member-1/cookie", diffs[1].Objects[0].ID)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		// when
+		_, err := diff.Revisions(logger, dir, "unknown", head, []string{"apps"}, []string{"components"})
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func newApplication(name, path string) string {
+	return `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: ` + name + `
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: ` + path + `
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+`
+}
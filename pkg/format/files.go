@@ -0,0 +1,117 @@
+package format
+
+import (
+	"bytes"
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// Result the outcome of the formatting of a file
+type Result struct {
+	// Path of the file (relative to the base directory)
+	Path string
+	// Changed true if the file was not in its canonical form
+	Changed bool
+	// Warnings about the file which cannot be fixed by formatting (eg: deprecated fields)
+	Warnings []string
+}
+
+// Files formats the Kustomization files in the `apps` and `components` paths, and the files which contain an Application
+// or an ApplicationSet in the `apps` paths (all paths are relative to the base directory).
+// The files are rewritten only when `write` is true (otherwise, the results tell which files would be changed).
+// Returns the results of the files which changed or have warnings, sorted by path.
+func Files(logger *log.Logger, afs afero.Afero, baseDir string, apps, components []string, write bool) ([]Result, error) {
+	results := map[string]Result{}
+	visited := map[string]bool{} // in case the `apps` and `components` paths overlap
+	walk := func(root string, manifests bool) error {
+		return afs.Walk(filepath.Join(baseDir, root), func(path string, info iofs.FileInfo, err error) error {
+			if err != nil {
+				logger.Error("prevent panic by handling failure", "path", path)
+				return err
+			}
+			if info.IsDir() || visited[path] {
+				return nil
+			}
+			visited[path] = true
+			var kustomization bool
+			switch {
+			case IsKustomization(info.Name()):
+				kustomization = true
+			case manifests && (filepath.Ext(path) == ".yaml" || filepath.Ext(path) == ".yml"):
+			default:
+				return nil
+			}
+			data, err := afs.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var result []byte
+			var warnings []string
+			if kustomization {
+				result, warnings, err = Kustomization(data)
+			} else {
+				if !isApplication(data) {
+					return nil
+				}
+				result, err = Manifests(data)
+			}
+			if err != nil {
+				rpath, _ := filepath.Rel(baseDir, path)
+				logger.Error("failed to format file", "path", rpath, "err", err)
+				return err
+			}
+			changed := !bytes.Equal(data, result)
+			if !changed && len(warnings) == 0 {
+				return nil
+			}
+			rpath, _ := filepath.Rel(baseDir, path)
+			results[path] = Result{
+				Path:     rpath,
+				Changed:  changed,
+				Warnings: warnings,
+			}
+			if changed && write {
+				logger.Debug("formatting file", "path", rpath)
+				return afs.WriteFile(path, result, info.Mode())
+			}
+			return nil
+		})
+	}
+	for _, p := range apps {
+		if err := walk(p, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range components {
+		if err := walk(p, false); err != nil {
+			return nil, err
+		}
+	}
+	sorted := make([]Result, 0, len(results))
+	for _, r := range results {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+	return sorted, nil
+}
+
+// returns true if the given YAML stream contains an Application or an ApplicationSet
+func isApplication(data []byte) bool {
+	nodes, err := kio.FromBytes(data)
+	if err != nil {
+		return false
+	}
+	for _, n := range nodes {
+		if n.GetApiVersion() == "argoproj.io/v1alpha1" && (n.GetKind() == "Application" || n.GetKind() == "ApplicationSet") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// order of the top-level fields of a Kustomization (unknown fields come after, in alphabetical order)
+var kustomizationFields = []string{
+	"apiVersion", "kind", "metadata",
+	"namespace", "namePrefix", "nameSuffix",
+	"labels", "commonLabels", "commonAnnotations", "buildMetadata",
+	"resources", "components", "crds", "configurations", "openapi",
+	"generators", "configMapGenerator", "secretGenerator", "generatorOptions",
+	"helmGlobals", "helmCharts",
+	"images", "replicas",
+	"patches", "patchesStrategicMerge", "patchesJson6902",
+	"replacements", "vars",
+	"transformers", "validators",
+	"sortOptions",
+}
+
+// deprecated fields of a Kustomization which cannot be migrated without changing the way they are interpreted,
+// with their replacement
+var deprecatedFields = map[string]string{
+	"commonLabels":          "labels",
+	"patchesStrategicMerge": "patches",
+	"patchesJson6902":       "patches",
+	"vars":                  "replacements",
+}
+
+// Kustomization returns the canonical form of the given Kustomization file:
+// - `apiVersion` and `kind` are set,
+// - the deprecated `bases`, `imageTags` and generator `envSource` fields are merged into `resources`, `images`
+// and `envSources` (as `kustomize edit fix` does),
+// - the entries of `resources` are sorted,
+// - the top-level fields are in the conventional order, the nested fields are sorted (see `Manifests`),
+// - non-empty lists and maps use the block style.
+// Comments are preserved. Returns the deprecated fields which remain (see `deprecatedFields`), as warnings.
+func Kustomization(data []byte) ([]byte, []string, error) {
+	var warnings []string
+	result, err := format(data, func(node *kyaml.RNode) error {
+		if err := fixKustomization(node); err != nil {
+			return err
+		}
+		if err := sortResources(node); err != nil {
+			return err
+		}
+		if _, err := (filters.FormatFilter{Process: blockStyle}).Filter([]*kyaml.RNode{node}); err != nil {
+			return err
+		}
+		orderFields(node.YNode(), kustomizationFields)
+		fields, err := node.Fields()
+		if err != nil {
+			return err
+		}
+		for _, k := range fields {
+			if replacement, found := deprecatedFields[k]; found {
+				warnings = append(warnings, fmt.Sprintf("deprecated field '%s' (use '%s' instead)", k, replacement))
+			}
+		}
+		return nil
+	})
+	return result, warnings, err
+}
+
+// Manifests returns the canonical form of the given stream of YAML documents (eg: Applications or ApplicationSets):
+// - the fields are in the conventional order of the Kubernetes objects (`apiVersion`, `kind`, `metadata`, `spec`, etc.),
+// then in alphabetical order,
+// - non-empty lists and maps use the block style,
+// - the indentation is 2 spaces and the list items are not indented.
+// Comments are preserved.
+func Manifests(data []byte) ([]byte, error) {
+	return format(data, func(node *kyaml.RNode) error {
+		_, err := (filters.FormatFilter{Process: blockStyle}).Filter([]*kyaml.RNode{node})
+		return err
+	})
+}
+
+// applies the given function on each document of the given YAML stream
+func format(data []byte, fn func(*kyaml.RNode) error) ([]byte, error) {
+	out := &bytes.Buffer{}
+	err := kio.Pipeline{
+		Inputs: []kio.Reader{&kio.ByteReader{Reader: bytes.NewReader(data)}},
+		Filters: []kio.Filter{kio.FilterFunc(func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			for _, n := range nodes {
+				// keep the comment at the top of the document (which is attached to its first field) at the top
+				header := ""
+				if content := n.YNode().Content; len(content) > 0 {
+					header, content[0].HeadComment = content[0].HeadComment, ""
+				}
+				if err := fn(n); err != nil {
+					return nil, err
+				}
+				if content := n.YNode().Content; len(content) > 0 && header != "" {
+					content[0].HeadComment = strings.TrimSpace(header + "\n" + content[0].HeadComment)
+				}
+			}
+			return nodes, nil
+		})},
+		Outputs: []kio.Writer{kio.ByteWriter{Writer: out}},
+	}.Execute()
+	return out.Bytes(), err
+}
+
+// same fixes as `kustomize edit fix`, limited to the fields which can be migrated as-is
+func fixKustomization(node *kyaml.RNode) error {
+	if node.GetKind() == "" {
+		node.SetKind(types.KustomizationKind)
+	}
+	if node.GetApiVersion() == "" {
+		version := types.KustomizationVersion
+		if node.GetKind() == types.ComponentKind {
+			version = types.ComponentVersion
+		}
+		node.SetApiVersion(version)
+	}
+	if err := moveEntries(node, "bases", "resources"); err != nil {
+		return err
+	}
+	if err := moveEntries(node, "imageTags", "images"); err != nil {
+		return err
+	}
+	for _, g := range []string{"configMapGenerator", "secretGenerator"} {
+		generators, err := node.Pipe(kyaml.Lookup(g))
+		if err != nil || generators == nil {
+			continue
+		}
+		if err := generators.VisitElements(func(gen *kyaml.RNode) error {
+			envSource, err := gen.Pipe(kyaml.Get("envSource"))
+			if err != nil || envSource == nil {
+				return err
+			}
+			envSources, err := gen.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "envSources"))
+			if err != nil {
+				return err
+			}
+			envSources.YNode().Content = append(envSources.YNode().Content, envSource.YNode())
+			return gen.PipeE(kyaml.Clear("envSource"))
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moves the entries of the `from` list at the end of the `to` list
+func moveEntries(node *kyaml.RNode, from, to string) error {
+	src, err := node.Pipe(kyaml.Lookup(from))
+	if err != nil || src == nil {
+		return err
+	}
+	dst, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, to))
+	if err != nil {
+		return err
+	}
+	dst.YNode().Content = append(dst.YNode().Content, src.YNode().Content...)
+	return node.PipeE(kyaml.Clear(from))
+}
+
+// sorts the entries of the `resources` of the Kustomization (with their comments)
+func sortResources(node *kyaml.RNode) error {
+	resources, err := node.Pipe(kyaml.Lookup("resources"))
+	if err != nil || resources == nil {
+		return err
+	}
+	sort.SliceStable(resources.YNode().Content, func(i, j int) bool {
+		return resources.YNode().Content[i].Value < resources.YNode().Content[j].Value
+	})
+	return nil
+}
+
+// sorts the fields of the given mapping node in the given order, then in alphabetical order
+func orderFields(node *kyaml.Node, order []string) {
+	type field struct {
+		key, value *kyaml.Node
+	}
+	fields := make([]field, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields = append(fields, field{key: node.Content[i], value: node.Content[i+1]})
+	}
+	rank := func(f field) int {
+		if i := slices.Index(order, f.key.Value); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, rj := rank(fields[i]), rank(fields[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return ri == len(order) && fields[i].key.Value < fields[j].key.Value
+	})
+	node.Content = node.Content[:0]
+	for _, f := range fields {
+		node.Content = append(node.Content, f.key, f.value)
+	}
+}
+
+// uses the block style for the non-empty lists and maps
+func blockStyle(n *kyaml.Node) error {
+	if (n.Kind == kyaml.SequenceNode || n.Kind == kyaml.MappingNode) && len(n.Content) > 0 {
+		n.Style &^= kyaml.FlowStyle
+	}
+	return nil
+}
+
+// IsKustomization returns true if the given file name is the name of a Kustomization file
+func IsKustomization(name string) bool {
+	return slices.Contains(konfig.RecognizedKustomizationFileNames(), name)
+}
@@ -0,0 +1,322 @@
+package format_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/format"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKustomization(t *testing.T) {
+
+	t.Run("canonical form", func(t *testing.T) {
+		// given
+		data := []byte(`# components of the cookie app
+resources: [service.yaml, deployment.yaml]
+namespace: cookie
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`)
+
+		// when
+		result, warnings, err := format.Kustomization(data)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+		assert.Equal(t, `# components of the cookie app
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: cookie
+resources:
+- deployment.yaml
+- service.yaml
+`, string(result))
+	})
+
+	t.Run("already in canonical form", func(t *testing.T) {
+		// given
+		data := []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml # the deployment
+- service.yaml
+images:
+- name: cookie
+  newTag: v1.0.0
+`)
+
+		// when
+		result, warnings, err := format.Kustomization(data)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+		assert.Equal(t, string(data), string(result))
+	})
+
+	t.Run("sorted resources with comments", func(t *testing.T) {
+		// given
+		data := []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+# the service
+- service.yaml
+- deployment.yaml # the deployment
+`)
+
+		// when
+		result, _, err := format.Kustomization(data)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml # the deployment
+# the service
+- service.yaml
+`, string(result))
+	})
+
+	t.Run("deprecated fields", func(t *testing.T) {
+		// given
+		data := []byte(`bases:
+- ../base
+resources:
+- configmap.yaml
+commonLabels:
+  app: cookie
+imageTags:
+- name: cookie
+  newTag: v1.0.0
+patchesStrategicMerge:
+- patch.yaml
+`)
+
+		// when
+		result, warnings, err := format.Kustomization(data)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+commonLabels:
+  app: cookie
+resources:
+- ../base
+- configmap.yaml
+images:
+- name: cookie
+  newTag: v1.0.0
+patchesStrategicMerge:
+- patch.yaml
+`, string(result))
+		assert.Equal(t, []string{
+			"deprecated field 'commonLabels' (use 'labels' instead)",
+			"deprecated field 'patchesStrategicMerge' (use 'patches' instead)",
+		}, warnings)
+	})
+
+	t.Run("component", func(t *testing.T) {
+		// given
+		data := []byte(`kind: Component
+resources:
+- configmap.yaml
+`)
+
+		// when
+		result, _, err := format.Kustomization(data)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+resources:
+- configmap.yaml
+`, string(result))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		// given
+		data := []byte(`resources: [`)
+
+		// when
+		_, _, err := format.Kustomization(data)
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestManifests(t *testing.T) {
+	// given
+	data := []byte(`# the cookie app
+spec:
+  project: default
+  destination: {namespace: cookie, server: https://kubernetes.default.svc}
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie # the component
+    targetRevision: HEAD
+metadata:
+  name: cookie
+  namespace: argocd
+kind: Application
+apiVersion: argoproj.io/v1alpha1
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+`)
+
+	// when
+	result, err := format.Manifests(data)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `# the cookie app
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+  namespace: argocd
+spec:
+  destination:
+    namespace: cookie
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie # the component
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+`, string(result))
+}
+
+func TestFiles(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/apps/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- app-pizza.yaml
+- app-cookie.yaml
+`,
+		"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination: {namespace: cookie}
+`,
+		"/path/to/apps/app-pizza.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pizza
+`,
+		"/path/to/apps/values.yaml": `foo: {bar: baz}
+`,
+		"/path/to/components/cookie/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+commonLabels:
+  app: cookie
+resources:
+- configmap.yaml
+`,
+		"/path/to/components/cookie/configmap.yaml": `kind: ConfigMap
+apiVersion: v1
+metadata: {name: cookie}
+`,
+	}
+	logger := log.New(os.Stderr)
+
+	t.Run("check", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		results, err := format.Files(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []format.Result{
+			{
+				Path:    "apps/app-cookie.yaml",
+				Changed: true,
+			},
+			{
+				Path:    "apps/kustomization.yaml",
+				Changed: true,
+			},
+			{
+				Path:     "components/cookie/kustomization.yaml",
+				Warnings: []string{"deprecated field 'commonLabels' (use 'labels' instead)"},
+			},
+		}, results)
+		// files are unchanged
+		data, err := afs.ReadFile("/path/to/apps/app-cookie.yaml")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "destination: {namespace: cookie}")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		results, err := format.Files(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, results, 3)
+		data, err := afs.ReadFile("/path/to/apps/app-cookie.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    namespace: cookie
+`, string(data))
+		data, err = afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- app-cookie.yaml
+- app-pizza.yaml
+`, string(data))
+		// files which are not Applications nor kustomizations are not formatted
+		data, err = afs.ReadFile("/path/to/apps/values.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "foo: {bar: baz}\n", string(data))
+		data, err = afs.ReadFile("/path/to/components/cookie/configmap.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "kind: ConfigMap\napiVersion: v1\nmetadata: {name: cookie}\n", string(data))
+
+		// when formatting again
+		results, err = format.Files(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []format.Result{
+			{
+				Path:     "components/cookie/kustomization.yaml",
+				Warnings: []string{"deprecated field 'commonLabels' (use 'labels' instead)"},
+			},
+		}, results)
+	})
+}
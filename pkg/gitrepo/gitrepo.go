@@ -0,0 +1,221 @@
+package gitrepo
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+)
+
+// ChangedFiles returns the paths of the files that changed in the local Git repository containing the given directory
+// since the given revision (branch, tag, commit, etc.), including the uncommitted changes in the worktree.
+// As with `git diff since...HEAD`, the changes are computed from the merge-base of the given revision and HEAD,
+// so that the changes made on the `since` branch after the current branch diverged from it are not reported.
+// The returned paths are relative to the given directory (eg: `dir/path/to/file`)
+func ChangedFiles(logger *log.Logger, dir, since string) ([]string, error) {
+	repo, err := open(dir)
+	if err != nil {
+		return nil, err
+	}
+	base, err := mergeBase(repo, since, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	names, err := diffTree(repo, base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range status {
+		if s.Staging != git.Unmodified || s.Worktree != git.Unmodified {
+			names[name] = true
+		}
+	}
+	files, err := relativeTo(repo, dir, names)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("found changed files", "since", since, "count", len(files))
+	return files, nil
+}
+
+// ChangedFilesBetween returns the paths of the files that changed in the local Git repository containing the given directory
+// between the `from` and `to` revisions (branch, tag, commit, etc.). The uncommitted changes in the worktree are ignored.
+// As with `git diff from...to`, the changes are computed from the merge-base of the two revisions.
+// The returned paths are relative to the given directory (eg: `dir/path/to/file`)
+func ChangedFilesBetween(logger *log.Logger, dir, from, to string) ([]string, error) {
+	repo, err := open(dir)
+	if err != nil {
+		return nil, err
+	}
+	base, err := mergeBase(repo, from, to)
+	if err != nil {
+		return nil, err
+	}
+	names, err := diffTree(repo, base, to)
+	if err != nil {
+		return nil, err
+	}
+	files, err := relativeTo(repo, dir, names)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("found changed files", "from", from, "to", to, "count", len(files))
+	return files, nil
+}
+
+// Checkout returns an in-memory filesystem with the files of the given revision (branch, tag, commit, etc.)
+// of the local Git repository containing the given directory. The worktree is left untouched.
+// The files are located at their absolute path in the worktree, so that the in-memory filesystem
+// can be used in place of the OS filesystem with an absolute base directory.
+// Symbolic links and submodules are skipped.
+func Checkout(logger *log.Logger, dir, revision string) (afero.Afero, error) {
+	afs := afero.Afero{
+		Fs: afero.NewMemMapFs(),
+	}
+	repo, err := open(dir)
+	if err != nil {
+		return afs, err
+	}
+	t, err := tree(repo, revision)
+	if err != nil {
+		return afs, err
+	}
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return afs, err
+	}
+	count := 0
+	if err := t.Files().ForEach(func(f *object.File) error {
+		if f.Mode == filemode.Symlink || f.Mode == filemode.Submodule {
+			logger.Debug("skipping file", "revision", revision, "path", f.Name)
+			return nil
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(root, filepath.FromSlash(f.Name))
+		if err := afs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		count++
+		return afs.WriteFile(path, []byte(contents), 0644)
+	}); err != nil {
+		return afs, err
+	}
+	logger.Debug("checked out revision in memory", "revision", revision, "files", count)
+	return afs, nil
+}
+
+func open(dir string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+}
+
+// returns the paths (relative to the root of the repository) of the files which differ between the given revisions
+func diffTree(repo *git.Repository, from, to string) (map[string]bool, error) {
+	fromTree, err := tree(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := tree(repo, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, c := range changes {
+		// `From` is empty when the file was added, `To` is empty when the file was deleted
+		if c.From.Name != "" {
+			names[c.From.Name] = true
+		}
+		if c.To.Name != "" {
+			names[c.To.Name] = true
+		}
+	}
+	return names, nil
+}
+
+// converts the paths relative to the root of the repository into paths relative to the given directory
+func relativeTo(repo *git.Repository, dir string, names map[string]bool) ([]string, error) {
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(names))
+	for name := range names {
+		rel, err := filepath.Rel(absDir, filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, filepath.Join(dir, rel))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func worktreeRoot(repo *git.Repository) (string, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(wt.Filesystem.Root())
+}
+
+// returns the hash of the best common ancestor of the given revisions,
+// or the hash of the first revision if they have no common history
+func mergeBase(repo *git.Repository, a, b string) (string, error) {
+	ca, err := commit(repo, a)
+	if err != nil {
+		return "", err
+	}
+	cb, err := commit(repo, b)
+	if err != nil {
+		return "", err
+	}
+	bases, err := ca.MergeBase(cb)
+	if err != nil {
+		return "", err
+	}
+	if len(bases) == 0 {
+		return ca.Hash.String(), nil
+	}
+	return bases[0].Hash.String(), nil
+}
+
+func tree(repo *git.Repository, revision string) (*object.Tree, error) {
+	c, err := commit(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+	return c.Tree()
+}
+
+func commit(repo *git.Repository, revision string) (*object.Commit, error) {
+	h, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(*h)
+}
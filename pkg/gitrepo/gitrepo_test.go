@@ -0,0 +1,227 @@
+package gitrepo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/gitrepo"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedFiles(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	dir, repo := test.NewRepository(t)
+	head := test.Commit(t, repo, dir, map[string]string{
+		"apps/app-cookie.yaml":                 "cookie",
+		"components/cookie/kustomization.yaml": "cookie",
+		"components/pasta/kustomization.yaml":  "pasta",
+	})
+	test.Commit(t, repo, dir, map[string]string{
+		"components/cookie/kustomization.yaml": "yummy cookie",
+	})
+	// uncommitted change
+	err := os.WriteFile(filepath.Join(dir, "components/pasta/kustomization.yaml"), []byte("yummy pasta"), 0600)
+	require.NoError(t, err)
+
+	t.Run("relative to repository root", func(t *testing.T) {
+		// when
+		files, err := gitrepo.ChangedFiles(logger, dir, head)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "components/cookie/kustomization.yaml"),
+			filepath.Join(dir, "components/pasta/kustomization.yaml"),
+		}, files)
+	})
+
+	t.Run("relative to subdirectory", func(t *testing.T) {
+		// when
+		files, err := gitrepo.ChangedFiles(logger, filepath.Join(dir, "components"), head)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "components/cookie/kustomization.yaml"),
+			filepath.Join(dir, "components/pasta/kustomization.yaml"),
+		}, files)
+	})
+
+	t.Run("diverged from revision", func(t *testing.T) {
+		// given
+		dir, repo := test.NewRepository(t)
+		base := test.Commit(t, repo, dir, map[string]string{
+			"components/cookie/kustomization.yaml": "cookie",
+			"components/pasta/kustomization.yaml":  "pasta",
+		})
+		// change on the upstream branch, after the current branch was created
+		upstream := test.Commit(t, repo, dir, map[string]string{
+			"components/pasta/kustomization.yaml": "upstream pasta",
+		})
+		wt, err := repo.Worktree()
+		require.NoError(t, err)
+		err = wt.Checkout(&git.CheckoutOptions{
+			Hash:   plumbing.NewHash(base),
+			Branch: plumbing.NewBranchReferenceName("feature"),
+			Create: true,
+		})
+		require.NoError(t, err)
+		test.Commit(t, repo, dir, map[string]string{
+			"components/cookie/kustomization.yaml": "yummy cookie",
+		})
+
+		// when
+		files, err := gitrepo.ChangedFiles(logger, dir, upstream)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "components/cookie/kustomization.yaml"),
+		}, files)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		// when
+		_, err := gitrepo.ChangedFiles(logger, dir, "unknown")
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestChangedFilesBetween(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	dir, repo := test.NewRepository(t)
+	base := test.Commit(t, repo, dir, map[string]string{
+		"apps/app-cookie.yaml":                 "cookie",
+		"components/cookie/kustomization.yaml": "cookie",
+		"components/pasta/kustomization.yaml":  "pasta",
+	})
+	head := test.Commit(t, repo, dir, map[string]string{
+		"components/cookie/kustomization.yaml": "yummy cookie",
+	})
+	// uncommitted change, which is ignored
+	err := os.WriteFile(filepath.Join(dir, "components/pasta/kustomization.yaml"), []byte("yummy pasta"), 0600)
+	require.NoError(t, err)
+
+	t.Run("between base and head", func(t *testing.T) {
+		// when
+		files, err := gitrepo.ChangedFilesBetween(logger, dir, base, head)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "components/cookie/kustomization.yaml"),
+		}, files)
+	})
+
+	t.Run("same revision", func(t *testing.T) {
+		// when
+		files, err := gitrepo.ChangedFilesBetween(logger, dir, head, "HEAD")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("diverged revisions", func(t *testing.T) {
+		// given
+		dir, repo := test.NewRepository(t)
+		base := test.Commit(t, repo, dir, map[string]string{
+			"components/cookie/kustomization.yaml": "cookie",
+			"components/pasta/kustomization.yaml":  "pasta",
+		})
+		// change on the upstream branch, after the feature branch was created
+		upstream := test.Commit(t, repo, dir, map[string]string{
+			"components/pasta/kustomization.yaml": "upstream pasta",
+		})
+		wt, err := repo.Worktree()
+		require.NoError(t, err)
+		err = wt.Checkout(&git.CheckoutOptions{
+			Hash:   plumbing.NewHash(base),
+			Branch: plumbing.NewBranchReferenceName("feature"),
+			Create: true,
+		})
+		require.NoError(t, err)
+		feature := test.Commit(t, repo, dir, map[string]string{
+			"components/cookie/kustomization.yaml": "yummy cookie",
+		})
+
+		// when
+		files, err := gitrepo.ChangedFilesBetween(logger, dir, upstream, feature)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "components/cookie/kustomization.yaml"),
+		}, files)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		// when
+		_, err := gitrepo.ChangedFilesBetween(logger, dir, base, "unknown")
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestCheckout(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	dir, repo := test.NewRepository(t)
+	base := test.Commit(t, repo, dir, map[string]string{
+		"apps/app-cookie.yaml":                 "cookie",
+		"components/cookie/kustomization.yaml": "cookie",
+	})
+	test.Commit(t, repo, dir, map[string]string{
+		"components/cookie/kustomization.yaml": "yummy cookie",
+	})
+	err := os.WriteFile(filepath.Join(dir, "components/cookie/kustomization.yaml"), []byte("uncommitted cookie"), 0600)
+	require.NoError(t, err)
+
+	t.Run("base revision", func(t *testing.T) {
+		// when
+		afs, err := gitrepo.Checkout(logger, dir, base)
+
+		// then
+		require.NoError(t, err)
+		data, err := afs.ReadFile(filepath.Join(dir, "components/cookie/kustomization.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "cookie", string(data))
+		data, err = afs.ReadFile(filepath.Join(dir, "apps/app-cookie.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "cookie", string(data))
+	})
+
+	t.Run("head revision", func(t *testing.T) {
+		// when
+		afs, err := gitrepo.Checkout(logger, filepath.Join(dir, "components"), "HEAD")
+
+		// then
+		require.NoError(t, err)
+		data, err := afs.ReadFile(filepath.Join(dir, "components/cookie/kustomization.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "yummy cookie", string(data))
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		// when
+		_, err := gitrepo.Checkout(logger, dir, "unknown")
+
+		// then
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+package images
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// the default registry of the images whose name has no registry
+const defaultRegistry = "docker.io"
+
+var semverTag = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Image a container image used by a workload
+type Image struct {
+	// Reference to the image, as specified in the workload
+	Reference string `json:"image"`
+	// Workload the kind and name of the workload (eg: `Deployment/cookie`)
+	Workload string `json:"workload"`
+	// Container the name of the container in the workload
+	Container string `json:"container"`
+}
+
+// the paths to the pod spec in the workloads, by kind
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// List returns the images of the containers, init containers and ephemeral containers of the given workloads
+// (Pods, Deployments, StatefulSets, DaemonSets, ReplicaSets, Jobs and CronJobs), sorted by workload and container
+func List(objs []*unstructured.Unstructured) []Image {
+	images := []Image{}
+	for _, obj := range objs {
+		path, found := podSpecPaths[obj.GetKind()]
+		if !found {
+			continue
+		}
+		for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			containers, _, _ := unstructured.NestedSlice(obj.Object, append(path, field)...)
+			for _, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _, _ := unstructured.NestedString(container, "name")
+				image, _, _ := unstructured.NestedString(container, "image")
+				images = append(images, Image{
+					Reference: image,
+					Workload:  obj.GetKind() + "/" + obj.GetName(),
+					Container: name,
+				})
+			}
+		}
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].Workload != images[j].Workload {
+			return images[i].Workload < images[j].Workload
+		}
+		return images[i].Container < images[j].Container
+	})
+	return images
+}
+
+// Inventory the images used by an Application
+type Inventory struct {
+	Application string `json:"application"`
+	// Path to the file in which the Application (or the ApplicationSet which generates it) is declared
+	Path   string  `json:"path"`
+	Images []Image `json:"images"`
+}
+
+// Reference the components of an image reference (`[registry/]repository[:tag][@digest]`)
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// Parse returns the components of the given image reference.
+// The registry is `docker.io` when the reference does not start with a hostname.
+func Parse(ref string) Reference {
+	r := Reference{}
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, r.Digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, r.Tag = name[:i], name[i+1:]
+	}
+	if host, repo, found := strings.Cut(name, "/"); found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		r.Registry, r.Repository = host, repo
+	} else {
+		r.Registry, r.Repository = defaultRegistry, name
+	}
+	return r
+}
+
+// Policy the rules that the images must follow
+type Policy struct {
+	// AllowedRegistries the registries (or registry/organization prefixes) from which images can be pulled.
+	// All registries are allowed when empty.
+	AllowedRegistries []string
+}
+
+// Check verifies that the given image reference is pinned by digest or uses a semver tag (and not `latest`),
+// and that it comes from one of the allowed registries
+func (p Policy) Check(ref string) error {
+	r := Parse(ref)
+	switch {
+	case r.Tag == "latest":
+		return fmt.Errorf("image '%s' uses the 'latest' tag", ref)
+	case r.Digest == "" && r.Tag == "":
+		return fmt.Errorf("image '%s' has no tag or digest", ref)
+	case r.Digest == "" && !semverTag.MatchString(r.Tag):
+		return fmt.Errorf("image '%s' is not pinned by digest and its tag is not a semantic version", ref)
+	}
+	return p.CheckRegistry(ref)
+}
+
+// CheckRegistry verifies that the given image reference comes from one of the allowed registries
+func (p Policy) CheckRegistry(ref string) error {
+	if len(p.AllowedRegistries) == 0 {
+		return nil
+	}
+	r := Parse(ref)
+	name := r.Registry + "/" + r.Repository
+	for _, allowed := range p.AllowedRegistries {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if r.Registry == allowed || strings.HasPrefix(name, allowed+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("image '%s' is not from an allowed registry (%s)", ref, strings.Join(p.AllowedRegistries, ", "))
+}
@@ -0,0 +1,132 @@
+package images_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	// given
+	objs, err := kube.SplitYAML([]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36.1
+      containers:
+      - name: cookie
+        image: quay.io/codeready-toolchain/cookie:v1.0.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: pasta
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: pasta
+            image: quay.io/codeready-toolchain/pasta@sha256:4d2ab5c1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie`))
+	require.NoError(t, err)
+
+	// when
+	imgs := images.List(objs)
+
+	// then
+	assert.Equal(t, []images.Image{
+		{
+			Reference: "quay.io/codeready-toolchain/pasta@sha256:4d2ab5c1",
+			Workload:  "CronJob/pasta",
+			Container: "pasta",
+		},
+		{
+			Reference: "quay.io/codeready-toolchain/cookie:v1.0.0",
+			Workload:  "Deployment/cookie",
+			Container: "cookie",
+		},
+		{
+			Reference: "busybox:1.36.1",
+			Workload:  "Deployment/cookie",
+			Container: "init",
+		},
+	}, imgs)
+}
+
+func TestParse(t *testing.T) {
+	for ref, expected := range map[string]images.Reference{
+		"nginx": {
+			Registry:   "docker.io",
+			Repository: "nginx",
+		},
+		"nginx:1.27": {
+			Registry:   "docker.io",
+			Repository: "nginx",
+			Tag:        "1.27",
+		},
+		"quay.io/codeready-toolchain/cookie:v1.0.0@sha256:4d2ab5c1": {
+			Registry:   "quay.io",
+			Repository: "codeready-toolchain/cookie",
+			Tag:        "v1.0.0",
+			Digest:     "sha256:4d2ab5c1",
+		},
+		"localhost:5000/cookie": {
+			Registry:   "localhost:5000",
+			Repository: "cookie",
+		},
+	} {
+		t.Run(ref, func(t *testing.T) {
+			assert.Equal(t, expected, images.Parse(ref))
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	// given
+	policy := images.Policy{
+		AllowedRegistries: []string{"quay.io/codeready-toolchain", "registry.redhat.io"},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		for _, ref := range []string{
+			"quay.io/codeready-toolchain/cookie:v1.0.0",
+			"quay.io/codeready-toolchain/cookie:1.2.3-rc.1",
+			"quay.io/codeready-toolchain/cookie@sha256:4d2ab5c1",
+			"registry.redhat.io/ubi9/ubi:main@sha256:4d2ab5c1",
+		} {
+			t.Run(ref, func(t *testing.T) {
+				assert.NoError(t, policy.Check(ref))
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for ref, msg := range map[string]string{
+			"quay.io/codeready-toolchain/cookie:latest":      "image 'quay.io/codeready-toolchain/cookie:latest' uses the 'latest' tag",
+			"quay.io/codeready-toolchain/cookie":             "image 'quay.io/codeready-toolchain/cookie' has no tag or digest",
+			"quay.io/codeready-toolchain/cookie:main":        "image 'quay.io/codeready-toolchain/cookie:main' is not pinned by digest and its tag is not a semantic version",
+			"quay.io/someone/cookie:v1.0.0":                  "image 'quay.io/someone/cookie:v1.0.0' is not from an allowed registry (quay.io/codeready-toolchain, registry.redhat.io)",
+			"nginx:1.27.0":                                   "image 'nginx:1.27.0' is not from an allowed registry (quay.io/codeready-toolchain, registry.redhat.io)",
+			"quay.io/codeready-toolchain-fork/cookie:v1.0.0": "image 'quay.io/codeready-toolchain-fork/cookie:v1.0.0' is not from an allowed registry (quay.io/codeready-toolchain, registry.redhat.io)",
+		} {
+			t.Run(ref, func(t *testing.T) {
+				assert.EqualError(t, policy.Check(ref), msg)
+			})
+		}
+	})
+}
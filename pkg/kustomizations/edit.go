@@ -0,0 +1,92 @@
+package kustomizations
+
+import (
+	"slices"
+
+	"github.com/spf13/afero"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// AddResources adds the given entries to the `resources` of the Kustomization file at the given path,
+// unless they are already present. If the existing entries are sorted, the new entries are inserted
+// so that they remain sorted, otherwise they are appended. Comments and formatting are preserved.
+// Returns true if the file was modified
+func AddResources(afs afero.Afero, kpath string, resources ...string) (bool, error) {
+	return editFile(afs, kpath, func(node *kyaml.RNode) (bool, error) {
+		list, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "resources"))
+		if err != nil {
+			return false, err
+		}
+		entries := make([]string, 0, len(list.YNode().Content))
+		for _, e := range list.YNode().Content {
+			entries = append(entries, e.Value)
+		}
+		sorted := slices.IsSorted(entries)
+		modified := false
+		for _, r := range resources {
+			if slices.Contains(entries, r) {
+				continue
+			}
+			i := len(entries)
+			if sorted {
+				i, _ = slices.BinarySearch(entries, r)
+			}
+			entries = slices.Insert(entries, i, r)
+			elem := kyaml.NewScalarRNode(r).YNode()
+			list.YNode().Content = slices.Insert(list.YNode().Content, i, elem)
+			modified = true
+		}
+		if modified {
+			// use the block style (eg: instead of `resources: []`)
+			list.YNode().Style &^= kyaml.FlowStyle
+		}
+		return modified, nil
+	})
+}
+
+// RemoveResources removes the given entries from the `resources` of the Kustomization file at the given path.
+// Comments and formatting of the other entries are preserved.
+// Returns true if the file was modified
+func RemoveResources(afs afero.Afero, kpath string, resources ...string) (bool, error) {
+	return editFile(afs, kpath, func(node *kyaml.RNode) (bool, error) {
+		list, err := node.Pipe(kyaml.Lookup("resources"))
+		if err != nil || list == nil {
+			return false, err
+		}
+		modified := false
+		list.YNode().Content = slices.DeleteFunc(list.YNode().Content, func(e *kyaml.Node) bool {
+			if slices.Contains(resources, e.Value) {
+				modified = true
+				return true
+			}
+			return false
+		})
+		return modified, nil
+	})
+}
+
+// parses the YAML file at the given path, applies the given edit function and writes the file back
+// if it was modified. Comments are preserved.
+func editFile(afs afero.Afero, path string, edit func(*kyaml.RNode) (bool, error)) (bool, error) {
+	data, err := afs.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	node, err := kyaml.Parse(string(data))
+	if err != nil {
+		return false, err
+	}
+	modified, err := edit(node)
+	if err != nil || !modified {
+		return false, err
+	}
+	result, err := node.String()
+	if err != nil {
+		return false, err
+	}
+	info, err := afs.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return true, afs.WriteFile(path, []byte(result), info.Mode())
+}
@@ -0,0 +1,197 @@
+package kustomizations_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddResources(t *testing.T) {
+
+	t.Run("sorted resources", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`# apps
+kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml # the best
+- app-pizza.yaml
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.AddResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml", "app-cookie.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, modified)
+		data, err := afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `# apps
+kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml # the best
+- app-pasta.yaml
+- app-pizza.yaml
+`, string(data))
+	})
+
+	t.Run("unsorted resources", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-pizza.yaml
+- app-cookie.yaml
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.AddResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, modified)
+		data, err := afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-pizza.yaml
+- app-cookie.yaml
+- app-pasta.yaml
+`, string(data))
+	})
+
+	t.Run("no resources", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.AddResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, modified)
+		data, err := afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-pasta.yaml
+`, string(data))
+	})
+
+	t.Run("empty resources", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`kind: Kustomization
+resources: []
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.AddResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml", "app-cookie.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, modified)
+		data, err := afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `kind: Kustomization
+resources:
+- app-cookie.yaml
+- app-pasta.yaml
+`, string(data))
+	})
+
+	t.Run("already present", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`kind: Kustomization
+resources:
+- app-pasta.yaml
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.AddResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, modified)
+	})
+}
+
+func TestRemoveResources(t *testing.T) {
+
+	t.Run("existing resources", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`# apps
+kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml # the best
+- app-pasta.yaml
+- app-pizza.yaml
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.RemoveResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml", "app-unknown.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, modified)
+		data, err := afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `# apps
+kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml # the best
+- app-pizza.yaml
+`, string(data))
+	})
+
+	t.Run("no resources", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := afs.WriteFile("/path/to/apps/kustomization.yaml", []byte(`kind: Kustomization
+`), 0644)
+		require.NoError(t, err)
+
+		// when
+		modified, err := kustomizations.RemoveResources(afs, "/path/to/apps/kustomization.yaml", "app-pasta.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, modified)
+	})
+}
@@ -0,0 +1,130 @@
+package kustomizations
+
+import (
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+)
+
+// Graph of the local references between the Kustomizations found in a set of directories
+type Graph struct {
+	// directories containing a Kustomization file, with the (cleaned) paths that they reference
+	references map[string][]string
+}
+
+// NewGraph walks the given paths (relative to the base directory) and collects the local references
+// of every Kustomization file
+func NewGraph(logger *log.Logger, afs afero.Afero, baseDir string, paths ...string) (*Graph, error) {
+	g := &Graph{
+		references: map[string][]string{},
+	}
+	for _, path := range paths {
+		p := filepath.Join(baseDir, path)
+		if err := afs.Walk(p, func(path string, info iofs.FileInfo, err error) error {
+			if err != nil {
+				logger.Error("prevent panic by handling failure", "path", path)
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			return g.Add(logger, afs, path)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Add (or refreshes) the references of the Kustomization file in the given directory.
+// The directory is removed from the graph if it does not contain a Kustomization file (anymore)
+func (g *Graph) Add(logger *log.Logger, afs afero.Afero, dir string) error {
+	dir = filepath.Clean(dir)
+	kpath, found := Lookup(logger, afs, dir)
+	if !found {
+		delete(g.references, dir)
+		return nil
+	}
+	kobj, err := Read(afs, kpath)
+	if err != nil {
+		return err
+	}
+	refs := []string{}
+	for _, r := range LocalReferences(kobj) {
+		refs = append(refs, filepath.Join(dir, r))
+	}
+	g.references[dir] = refs
+	return nil
+}
+
+// Dirs returns the sorted list of directories with a Kustomization in the graph
+func (g *Graph) Dirs() []string {
+	dirs := make([]string, 0, len(g.references))
+	for dir := range g.references {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// References returns the paths referenced by the Kustomization in the given directory
+func (g *Graph) References(dir string) []string {
+	return g.references[filepath.Clean(dir)]
+}
+
+// Affected returns the sorted list of directories with a Kustomization which depend on any of the given files:
+// - the directories containing the files (or one of their parent directories),
+// - the directories whose Kustomization reference the files,
+// - and transitively, the directories whose Kustomization reference another affected directory.
+func (g *Graph) Affected(files ...string) []string {
+	affected := map[string]bool{}
+	queue := []string{}
+	mark := func(dir string) {
+		if !affected[dir] {
+			affected[dir] = true
+			queue = append(queue, dir)
+		}
+	}
+	for _, f := range files {
+		f = filepath.Clean(f)
+		for dir := range g.references {
+			if f == dir || Contains(dir, f) {
+				mark(dir)
+			}
+		}
+		for dir, refs := range g.references {
+			for _, r := range refs {
+				if f == r || Contains(r, f) {
+					mark(dir)
+				}
+			}
+		}
+	}
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		for dir, refs := range g.references {
+			for _, r := range refs {
+				if r == d {
+					mark(dir)
+				}
+			}
+		}
+	}
+	result := make([]string, 0, len(affected))
+	for dir := range affected {
+		result = append(result, dir)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Contains returns true if the given path is located in the given directory (or one of its subdirectories)
+func Contains(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,98 @@
+package kustomizations_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffected(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	afs := afero.Afero{
+		Fs: afero.NewMemMapFs(),
+	}
+	for path, data := range map[string]string{
+		"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml
+- app-pasta.yaml`,
+		"/path/to/apps/app-cookie.yaml": "",
+		"/path/to/apps/app-pasta.yaml":  "",
+		"/path/to/components/shared/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- rbac.yaml`,
+		"/path/to/components/shared/rbac.yaml": "",
+		"/path/to/components/cookie/base/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- ../../shared
+- deployment.yaml`,
+		"/path/to/components/cookie/base/deployment.yaml": "",
+		"/path/to/components/cookie/dev/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- ../base
+patches:
+- path: patch.yaml`,
+		"/path/to/components/cookie/dev/patch.yaml": "",
+		"/path/to/components/pasta/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- https://github.com/codeready-toolchain/sandbox-argocd/components/pasta?ref=master`,
+	} {
+		err := afs.WriteFile(path, []byte(data), 0755)
+		require.NoError(t, err)
+	}
+	g, err := kustomizations.NewGraph(logger, afs, "/path/to", "apps", "components")
+	require.NoError(t, err)
+
+	t.Run("shared resource", func(t *testing.T) {
+		// when
+		affected := g.Affected("/path/to/components/shared/rbac.yaml")
+
+		// then
+		assert.Equal(t, []string{
+			"/path/to/components/cookie/base",
+			"/path/to/components/cookie/dev",
+			"/path/to/components/shared",
+		}, affected)
+	})
+
+	t.Run("overlay patch", func(t *testing.T) {
+		// when
+		affected := g.Affected("/path/to/components/cookie/dev/patch.yaml")
+
+		// then
+		assert.Equal(t, []string{
+			"/path/to/components/cookie/dev",
+		}, affected)
+	})
+
+	t.Run("application", func(t *testing.T) {
+		// when
+		affected := g.Affected("/path/to/apps/app-pasta.yaml")
+
+		// then
+		assert.Equal(t, []string{
+			"/path/to/apps",
+		}, affected)
+	})
+
+	t.Run("unrelated file", func(t *testing.T) {
+		// when
+		affected := g.Affected("/path/to/README.md")
+
+		// then
+		assert.Empty(t, affected)
+	})
+}
@@ -0,0 +1,121 @@
+package kustomizations
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/kustomize/api/types"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Lookup returns the path to the Kustomization file in the given directory, if it exists
+func Lookup(logger *log.Logger, afs afero.Afero, dir string) (string, bool) {
+	return lookup(logger, dir, func(p string) bool {
+		_, err := afs.Open(p)
+		return err == nil
+	})
+}
+
+// LookupFS returns the path to the Kustomization file in the given directory of the Kustomize filesystem
+// (eg: the in-memory filesystem used to run `kustomize build`), if it exists
+func LookupFS(logger *log.Logger, fsys kfsys.FileSystem, dir string) (string, bool) {
+	return lookup(logger, dir, fsys.Exists)
+}
+
+func lookup(logger *log.Logger, dir string, exists func(path string) bool) (string, bool) {
+	for _, k := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		p := filepath.Join(dir, k)
+		if exists(p) {
+			logger.Debug("found Kustomization file", "path", p)
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Read reads and parses the Kustomization file at the given path
+func Read(afs afero.Afero, kpath string) (*types.Kustomization, error) {
+	data, err := afs.ReadFile(kpath)
+	if err != nil {
+		return nil, err
+	}
+	kobj := &types.Kustomization{}
+	if err := kobj.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return kobj, nil
+}
+
+// LocalReferences returns the paths of all local files and directories referenced in the given Kustomization
+// (relative to the Kustomization file)
+func LocalReferences(kobj *types.Kustomization) []string {
+	refs := []string{}
+	refs = append(refs, kobj.Resources...)
+	refs = append(refs, kobj.Bases...) //nolint:staticcheck
+	refs = append(refs, kobj.Components...)
+	refs = append(refs, kobj.Crds...)
+	refs = append(refs, kobj.Configurations...)
+	refs = append(refs, kobj.Generators...)
+	refs = append(refs, kobj.Transformers...)
+	refs = append(refs, kobj.Validators...)
+	for _, m := range kobj.PatchesStrategicMerge { //nolint:staticcheck
+		refs = append(refs, string(m))
+	}
+	for _, p := range kobj.PatchesJson6902 { //nolint:staticcheck
+		refs = append(refs, p.Path)
+	}
+	for _, p := range kobj.Patches {
+		refs = append(refs, p.Path)
+	}
+	for _, r := range kobj.Replacements {
+		refs = append(refs, r.Path)
+	}
+	for _, g := range kobj.ConfigMapGenerator {
+		refs = append(refs, GeneratorFiles(g.KvPairSources)...)
+	}
+	for _, g := range kobj.SecretGenerator {
+		refs = append(refs, GeneratorFiles(g.KvPairSources)...)
+	}
+	refs = append(refs, kobj.OpenAPI["path"])
+	result := []string{}
+	for _, r := range refs {
+		if r == "" || IsInline(r) || IsRemote(r) {
+			continue
+		}
+		result = append(result, filepath.Clean(r))
+	}
+	return result
+}
+
+// GeneratorFiles returns the paths of the files used in a ConfigMap/Secret generator.
+// File sources may be specified with a key (eg: `key=path/to/file`)
+func GeneratorFiles(src types.KvPairSources) []string {
+	files := []string{}
+	for _, f := range src.FileSources {
+		if i := strings.LastIndex(f, "="); i > 0 {
+			files = append(files, f[i+1:])
+		} else {
+			files = append(files, f)
+		}
+	}
+	files = append(files, src.EnvSources...)
+	if src.EnvSource != "" {
+		files = append(files, src.EnvSource)
+	}
+	return files
+}
+
+// IsInline returns true if the given entry is an inline patch or transformer config instead of a path
+func IsInline(r string) bool {
+	return strings.Contains(r, "\n")
+}
+
+// IsRemote returns true if the given entry refers to a remote resource (eg: a Git repository or a URL)
+func IsRemote(r string) bool {
+	return strings.Contains(r, "://") ||
+		strings.HasPrefix(r, "git@") ||
+		strings.HasPrefix(r, "github.com/") ||
+		strings.Contains(r, "?ref=")
+}
@@ -0,0 +1,218 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// the deprecated fields of a Kustomization, in the order in which they are migrated, with their replacement
+var fields = []struct {
+	name        string
+	replacement string
+	migrate     func(node *kyaml.RNode, refs varReferences) error
+}{
+	{name: "bases", replacement: "resources", migrate: migrateBases},
+	{name: "patchesStrategicMerge", replacement: "patches", migrate: migratePatchesStrategicMerge},
+	{name: "patchesJson6902", replacement: "patches", migrate: migratePatchesJSON6902},
+	{name: "commonLabels", replacement: "labels", migrate: migrateCommonLabels},
+	{name: "vars", replacement: "replacements", migrate: migrateVars},
+}
+
+// `bases` are appended to the `resources`
+func migrateBases(node *kyaml.RNode, _ varReferences) error {
+	bases, err := node.Pipe(kyaml.Lookup("bases"))
+	if err != nil {
+		return err
+	}
+	resources, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "resources"))
+	if err != nil {
+		return err
+	}
+	resources.YNode().Content = append(resources.YNode().Content, bases.YNode().Content...)
+	return node.PipeE(kyaml.Clear("bases"))
+}
+
+// `patchesStrategicMerge` (paths or inline patches) are inserted at the beginning of the `patches`,
+// since kustomize applies them before the other patches
+func migratePatchesStrategicMerge(node *kyaml.RNode, _ varReferences) error {
+	psm, err := node.Pipe(kyaml.Lookup("patchesStrategicMerge"))
+	if err != nil {
+		return err
+	}
+	migrated := []*kyaml.Node{}
+	for _, p := range psm.YNode().Content {
+		patch := kyaml.NewMapRNode(nil)
+		key := "path"
+		if kustomizations.IsInline(p.Value) {
+			key = "patch"
+		}
+		if err := patch.PipeE(kyaml.SetField(key, kyaml.NewRNode(p))); err != nil {
+			return err
+		}
+		migrated = append(migrated, patch.YNode())
+	}
+	patches, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "patches"))
+	if err != nil {
+		return err
+	}
+	patches.YNode().Content = append(migrated, patches.YNode().Content...)
+	return node.PipeE(kyaml.Clear("patchesStrategicMerge"))
+}
+
+// `patchesJson6902` are appended to the `patches` (they have the same `target`, `path` and `patch` fields)
+func migratePatchesJSON6902(node *kyaml.RNode, _ varReferences) error {
+	json6902, err := node.Pipe(kyaml.Lookup("patchesJson6902"))
+	if err != nil {
+		return err
+	}
+	patches, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "patches"))
+	if err != nil {
+		return err
+	}
+	patches.YNode().Content = append(patches.YNode().Content, json6902.YNode().Content...)
+	return node.PipeE(kyaml.Clear("patchesJson6902"))
+}
+
+// `commonLabels` are appended to the `labels`, with `includeSelectors: true` (as `kustomize edit fix` does)
+func migrateCommonLabels(node *kyaml.RNode, _ varReferences) error {
+	commonLabels, err := node.Pipe(kyaml.Lookup("commonLabels"))
+	if err != nil {
+		return err
+	}
+	label := kyaml.NewMapRNode(nil)
+	if err := label.PipeE(kyaml.SetField("pairs", commonLabels)); err != nil {
+		return err
+	}
+	includeSelectors := kyaml.NewRNode(&kyaml.Node{Kind: kyaml.ScalarNode, Tag: kyaml.NodeTagBool, Value: "true"})
+	if err := label.PipeE(kyaml.SetField("includeSelectors", includeSelectors)); err != nil {
+		return err
+	}
+	labels, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "labels"))
+	if err != nil {
+		return err
+	}
+	labels.YNode().Content = append(labels.YNode().Content, label.YNode())
+	return node.PipeE(kyaml.Clear("commonLabels"))
+}
+
+// `vars` are converted into `replacements`, whose targets are the fields in which the variables are referenced
+// (variables which are not referenced are dropped)
+func migrateVars(node *kyaml.RNode, refs varReferences) error {
+	vars, err := node.Pipe(kyaml.Lookup("vars"))
+	if err != nil {
+		return err
+	}
+	migrated := []*kyaml.Node{}
+	for _, v := range vars.YNode().Content {
+		name, err := kyaml.NewRNode(v).GetString("name")
+		if err != nil {
+			return err
+		}
+		targets := refs[name]
+		if len(targets) == 0 {
+			continue
+		}
+		replacement, err := newReplacement(kyaml.NewRNode(v), targets)
+		if err != nil {
+			return fmt.Errorf("failed to convert variable '%s': %w", name, err)
+		}
+		migrated = append(migrated, replacement.YNode())
+	}
+	if len(migrated) > 0 {
+		replacements, err := node.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, "replacements"))
+		if err != nil {
+			return err
+		}
+		replacements.YNode().Content = append(replacements.YNode().Content, migrated...)
+	}
+	return node.PipeE(kyaml.Clear("vars"))
+}
+
+// returns a replacement whose source is the object and field of the given variable
+func newReplacement(v *kyaml.RNode, targets []varTarget) (*kyaml.RNode, error) {
+	source := kyaml.NewMapRNode(nil)
+	objref, err := v.Pipe(kyaml.Lookup("objref"))
+	if err != nil {
+		return nil, err
+	}
+	if objref == nil {
+		return nil, fmt.Errorf("missing 'objref'")
+	}
+	gv := schema.GroupVersion{}
+	if apiVersion := objref.Field("apiVersion"); apiVersion != nil {
+		if gv, err = schema.ParseGroupVersion(apiVersion.Value.YNode().Value); err != nil {
+			return nil, err
+		}
+	}
+	if err := setStringFields(source,
+		[2]string{"group", gv.Group},
+		[2]string{"version", gv.Version},
+		[2]string{"kind", stringField(objref, "kind")},
+		[2]string{"name", stringField(objref, "name")},
+		[2]string{"namespace", stringField(objref, "namespace")},
+	); err != nil {
+		return nil, err
+	}
+	// variables refer to the `metadata.name` of their object by default
+	fieldPath := "metadata.name"
+	if fieldref, _ := v.Pipe(kyaml.Lookup("fieldref", "fieldpath")); fieldref != nil {
+		fieldPath = fieldref.YNode().Value
+	}
+	// variables use the `spec.ports[0].port` notation while replacements use the `spec.ports.0.port` notation
+	fieldPath = strings.NewReplacer("[", ".", "]", "").Replace(fieldPath)
+	if err := source.PipeE(kyaml.SetField("fieldPath", kyaml.NewStringRNode(fieldPath))); err != nil {
+		return nil, err
+	}
+
+	replacement := kyaml.NewMapRNode(nil)
+	if err := replacement.PipeE(kyaml.SetField("source", source)); err != nil {
+		return nil, err
+	}
+	ts := kyaml.NewListRNode()
+	for _, t := range targets {
+		target := kyaml.NewMapRNode(nil)
+		selector := kyaml.NewMapRNode(nil)
+		if err := setStringFields(selector, [2]string{"kind", t.kind}, [2]string{"name", t.name}, [2]string{"namespace", t.namespace}); err != nil {
+			return nil, err
+		}
+		if err := target.PipeE(kyaml.SetField("select", selector)); err != nil {
+			return nil, err
+		}
+		if err := target.PipeE(kyaml.SetField("fieldPaths", kyaml.NewListRNode(t.fieldPaths...))); err != nil {
+			return nil, err
+		}
+		if err := ts.PipeE(kyaml.Append(target.YNode())); err != nil {
+			return nil, err
+		}
+	}
+	if err := replacement.PipeE(kyaml.SetField("targets", ts)); err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// returns the value of the given field, or an empty string if it does not exist
+func stringField(node *kyaml.RNode, name string) string {
+	if f := node.Field(name); f != nil {
+		return f.Value.YNode().Value
+	}
+	return ""
+}
+
+// sets the given key/value fields in the given mapping node, in order. Fields with an empty value are skipped
+func setStringFields(node *kyaml.RNode, fields ...[2]string) error {
+	for _, f := range fields {
+		if f[1] == "" {
+			continue
+		}
+		if err := node.PipeE(kyaml.SetField(f[0], kyaml.NewStringRNode(f[1]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
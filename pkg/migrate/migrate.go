@@ -0,0 +1,222 @@
+package migrate
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Migration of a deprecated field of a Kustomization file
+type Migration struct {
+	// Path of the Kustomization file (relative to the base directory)
+	Path        string
+	Field       string
+	Replacement string
+	// Err the reason why the field was not migrated (nil if the field was migrated)
+	Err error
+}
+
+// Kustomizations migrates the deprecated fields of the Kustomization files found in the given paths (relative to the base directory)
+// to their modern equivalent: `bases` to `resources`, `patchesStrategicMerge` and `patchesJson6902` to `patches`, `commonLabels`
+// to `labels` and `vars` to `replacements`.
+// Each migration is verified by running `kustomize build` on the directories which depend on the Kustomization file,
+// before and after the migration: a migration which changes the rendered objects is refused.
+// The files are rewritten only when `write` is true (otherwise, the migrations tell which fields would be migrated).
+func Kustomizations(logger *log.Logger, afs afero.Afero, baseDir string, paths []string, write bool) ([]Migration, error) {
+	graph, err := kustomizations.NewGraph(logger, afs, baseDir, paths...)
+	if err != nil {
+		return nil, err
+	}
+	// migrations are verified in memory
+	fsys, err := validation.NewInMemoryFS(logger, afs, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	migrations := []Migration{}
+	for _, dir := range graph.Dirs() {
+		kpath, found := kustomizations.Lookup(logger, afs, dir)
+		if !found {
+			continue
+		}
+		ms, err := migrateFile(logger, afs, fsys, graph, baseDir, kpath, write)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, ms...)
+	}
+	return migrations, nil
+}
+
+// migrates the deprecated fields of the given Kustomization file, one by one
+func migrateFile(logger *log.Logger, afs afero.Afero, fsys kfsys.FileSystem, graph *kustomizations.Graph, baseDir, kpath string, write bool) ([]Migration, error) {
+	data, err := afs.ReadFile(kpath)
+	if err != nil {
+		return nil, err
+	}
+	node, err := kyaml.Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	rkpath, _ := filepath.Rel(baseDir, kpath)
+	migrations := []Migration{}
+	var baseline map[string][]*unstructured.Unstructured
+	current := string(data)
+	for _, f := range fields {
+		if node.Field(f.name) == nil {
+			continue
+		}
+		if baseline == nil {
+			baseline = build(logger, fsys, graph.Affected(kpath))
+		}
+		logger.Debug("migrating deprecated field", "path", rkpath, "field", f.name)
+		m := Migration{
+			Path:        rkpath,
+			Field:       f.name,
+			Replacement: f.replacement,
+		}
+		candidate, err := migrateField(logger, fsys, kpath, node, f.name, f.migrate)
+		if err == nil {
+			err = fsys.WriteFile(kpath, []byte(candidate))
+			if err == nil {
+				err = verify(logger, fsys, baseDir, baseline)
+			}
+		}
+		if err != nil {
+			// restore the previous version of the file
+			if err := fsys.WriteFile(kpath, []byte(current)); err != nil {
+				return nil, err
+			}
+			m.Err = err
+			migrations = append(migrations, m)
+			continue
+		}
+		if node, err = kyaml.Parse(candidate); err != nil {
+			return nil, err
+		}
+		current = candidate
+		migrations = append(migrations, m)
+	}
+	if write && current != string(data) {
+		info, err := afs.Stat(kpath)
+		if err != nil {
+			return nil, err
+		}
+		if err := afs.WriteFile(kpath, []byte(current), info.Mode()); err != nil {
+			return nil, err
+		}
+	}
+	return migrations, nil
+}
+
+// returns the content of the Kustomization file after the migration of the given field
+func migrateField(logger *log.Logger, fsys kfsys.FileSystem, kpath string, node *kyaml.RNode, field string, migrate func(*kyaml.RNode, varReferences) error) (string, error) {
+	var refs varReferences
+	if field == "vars" {
+		var err error
+		if refs, err = lookupVarReferences(logger, fsys, kpath, node); err != nil {
+			return "", err
+		}
+	}
+	candidate := node.Copy()
+	if err := migrate(candidate, refs); err != nil {
+		return "", err
+	}
+	return candidate.String()
+}
+
+// runs `kustomize build` on the Kustomization without its `vars`, to find the fields in which the variables are referenced
+func lookupVarReferences(logger *log.Logger, fsys kfsys.FileSystem, kpath string, node *kyaml.RNode) (varReferences, error) {
+	names := []string{}
+	vars, err := node.Pipe(kyaml.Lookup("vars"))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vars.YNode().Content {
+		names = append(names, stringField(kyaml.NewRNode(v), "name"))
+	}
+	withoutVars := node.Copy()
+	if err := withoutVars.PipeE(kyaml.Clear("vars")); err != nil {
+		return nil, err
+	}
+	data, err := withoutVars.String()
+	if err != nil {
+		return nil, err
+	}
+	original, err := fsys.ReadFile(kpath)
+	if err != nil {
+		return nil, err
+	}
+	if err := fsys.WriteFile(kpath, []byte(data)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := fsys.WriteFile(kpath, original); err != nil {
+			logger.Error("failed to restore Kustomization", "path", kpath, "err", err)
+		}
+	}()
+	objs, err := render.Kustomize(fsys, filepath.Dir(kpath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the references of the variables: %w", err)
+	}
+	return findVarReferences(objs, names)
+}
+
+// runs `kustomize build` on the given directories. The directories in which the build fails are ignored
+// (eg: Kustomize Components, which cannot be built on their own)
+func build(logger *log.Logger, fsys kfsys.FileSystem, dirs []string) map[string][]*unstructured.Unstructured {
+	result := map[string][]*unstructured.Unstructured{}
+	for _, dir := range dirs {
+		objs, err := render.Kustomize(fsys, dir)
+		if err != nil {
+			logger.Debug("skipping directory in which kustomize build fails", "path", dir, "err", err)
+			continue
+		}
+		result[dir] = objs
+	}
+	return result
+}
+
+// verifies that `kustomize build` renders the same objects as in the baseline
+func verify(logger *log.Logger, fsys kfsys.FileSystem, baseDir string, baseline map[string][]*unstructured.Unstructured) error {
+	if len(baseline) == 0 {
+		return fmt.Errorf("cannot verify the migration since kustomize build fails in all the directories which depend on the file")
+	}
+	dirs := make([]string, 0, len(baseline))
+	for dir := range baseline {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		expected := baseline[dir]
+		rdir, _ := filepath.Rel(baseDir, dir)
+		logger.Debug("verifying kustomize build", "path", rdir)
+		actual, err := render.Kustomize(fsys, dir)
+		if err != nil {
+			return fmt.Errorf("kustomize build fails in '%s': %w", rdir, err)
+		}
+		diffs, err := diff.Objects(expected, actual)
+		if err != nil {
+			return err
+		}
+		if len(diffs) > 0 {
+			ids := make([]string, 0, len(diffs))
+			for _, d := range diffs {
+				ids = append(ids, fmt.Sprintf("%s (%s)", d.ID, d.Status))
+			}
+			return fmt.Errorf("the objects rendered in '%s' would change: %s", rdir, strings.Join(ids, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package migrate_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/migrate"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  selector:
+    matchLabels:
+      app: cookie
+  template:
+    metadata:
+      labels:
+        app: cookie
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/sandbox/cookie:v1.0.0
+        env:
+        - name: SERVICE
+          value: $(SERVICE)
+`
+
+const service = `apiVersion: v1
+kind: Service
+metadata:
+  name: cookie
+spec:
+  selector:
+    app: cookie
+  ports:
+  - port: 8080
+`
+
+func TestKustomizations(t *testing.T) {
+
+	logger := log.New(os.Stderr)
+
+	base := map[string]string{
+		"/path/to/components/cookie/base/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+- service.yaml
+`,
+		"/path/to/components/cookie/base/deployment.yaml": deployment,
+		"/path/to/components/cookie/base/service.yaml":    service,
+		"/path/to/components/cookie/overlay/replicas.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  replicas: 2
+`,
+	}
+	overlay := `# the overlay
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: dev-
+bases:
+- ../base
+commonLabels:
+  env: dev # the environment
+patchesStrategicMerge:
+- replicas.yaml
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: cookie
+  patch: |-
+    - op: replace
+      path: /spec/template/spec/containers/0/image
+      value: quay.io/sandbox/cookie:v1.1.0
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: cookie
+`
+	t.Run("all fields", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, base, map[string]string{
+			"/path/to/components/cookie/overlay/kustomization.yaml": overlay,
+		})
+
+		// when
+		migrations, err := migrate.Kustomizations(logger, afs, "/path/to", []string{"components"}, true)
+
+		// then
+		require.NoError(t, err)
+		path := "components/cookie/overlay/kustomization.yaml"
+		assert.Equal(t, []migrate.Migration{
+			{Path: path, Field: "bases", Replacement: "resources"},
+			{Path: path, Field: "patchesStrategicMerge", Replacement: "patches"},
+			{Path: path, Field: "patchesJson6902", Replacement: "patches"},
+			{Path: path, Field: "commonLabels", Replacement: "labels"},
+			{Path: path, Field: "vars", Replacement: "replacements"},
+		}, migrations)
+		data, err := afs.ReadFile("/path/to/components/cookie/overlay/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `# the overlay
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: dev-
+resources:
+- ../base
+patches:
+- path: replicas.yaml
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: cookie
+  patch: |-
+    - op: replace
+      path: /spec/template/spec/containers/0/image
+      value: quay.io/sandbox/cookie:v1.1.0
+labels:
+- pairs:
+    env: dev # the environment
+  includeSelectors: true
+replacements:
+- source:
+    version: v1
+    kind: Service
+    name: cookie
+    fieldPath: metadata.name
+  targets:
+  - select:
+      kind: Deployment
+      name: dev-cookie
+    fieldPaths:
+    - spec.template.spec.containers.[name=cookie].env.[name=SERVICE].value
+`, string(data))
+	})
+
+	t.Run("vars with a namespace", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, base, map[string]string{
+			"/path/to/components/cookie/overlay/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: cookie
+resources:
+- ../base
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: cookie
+    namespace: cookie
+`,
+		})
+
+		// when
+		migrations, err := migrate.Kustomizations(logger, afs, "/path/to", []string{"components"}, true)
+
+		// then
+		require.NoError(t, err)
+		path := "components/cookie/overlay/kustomization.yaml"
+		assert.Equal(t, []migrate.Migration{
+			{Path: path, Field: "vars", Replacement: "replacements"},
+		}, migrations)
+		data, err := afs.ReadFile("/path/to/components/cookie/overlay/kustomization.yaml")
+		require.NoError(t, err)
+		// fields are always in the same order
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: cookie
+resources:
+- ../base
+replacements:
+- source:
+    version: v1
+    kind: Service
+    name: cookie
+    namespace: cookie
+    fieldPath: metadata.name
+  targets:
+  - select:
+      kind: Deployment
+      name: cookie
+      namespace: cookie
+    fieldPaths:
+    - spec.template.spec.containers.[name=cookie].env.[name=SERVICE].value
+`, string(data))
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, base, map[string]string{
+			"/path/to/components/cookie/overlay/kustomization.yaml": overlay,
+		})
+
+		// when
+		migrations, err := migrate.Kustomizations(logger, afs, "/path/to", []string{"components"}, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, migrations, 5)
+		data, err := afs.ReadFile("/path/to/components/cookie/overlay/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, overlay, string(data))
+	})
+
+	t.Run("refuse migrations which change the rendered objects", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, base, map[string]string{
+			// the JSON 6902 patch is applied after the labels, but `patches` are applied before the labels
+			"/path/to/components/cookie/overlay/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../base
+commonLabels:
+  env: dev
+patchesJson6902:
+- target:
+    kind: Service
+    name: cookie
+  patch: |-
+    - op: replace
+      path: /metadata/labels
+      value:
+        team: sandbox
+`,
+		})
+
+		// when
+		migrations, err := migrate.Kustomizations(logger, afs, "/path/to", []string{"components"}, true)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.EqualError(t, migrations[0].Err, "the objects rendered in 'components/cookie/overlay' would change: Service/cookie (modified)")
+		assert.NoError(t, migrations[1].Err)
+		data, err := afs.ReadFile("/path/to/components/cookie/overlay/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../base
+patchesJson6902:
+- target:
+    kind: Service
+    name: cookie
+  patch: |-
+    - op: replace
+      path: /metadata/labels
+      value:
+        team: sandbox
+labels:
+- pairs:
+    env: dev
+  includeSelectors: true
+`, string(data))
+	})
+
+	t.Run("refuse variables referenced in a part of a value", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, base, map[string]string{
+			"/path/to/components/cookie/base/deployment.yaml": strings.ReplaceAll(deployment, "value: $(SERVICE)", "value: http://$(SERVICE):8080"),
+			"/path/to/components/cookie/overlay/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../base
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: cookie
+`,
+		})
+
+		// when
+		migrations, err := migrate.Kustomizations(logger, afs, "/path/to", []string{"components"}, true)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		assert.EqualError(t, migrations[0].Err, "variable 'SERVICE' is referenced in a part of the value of 'spec.template.spec.containers.[name=cookie].env.[name=SERVICE].value' in Deployment/cookie")
+	})
+
+	t.Run("refuse variables referenced in another kustomization", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, base, map[string]string{
+			"/path/to/components/cookie/base/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- service.yaml
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: cookie
+`,
+			"/path/to/components/cookie/overlay/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../base
+- deployment.yaml
+`,
+			"/path/to/components/cookie/overlay/deployment.yaml": deployment,
+		})
+
+		// when
+		migrations, err := migrate.Kustomizations(logger, afs, "/path/to", []string{"components"}, true)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		assert.Equal(t, "components/cookie/base/kustomization.yaml", migrations[0].Path)
+		assert.EqualError(t, migrations[0].Err, "the objects rendered in 'components/cookie/overlay' would change: Deployment/cookie (modified)")
+	})
+}
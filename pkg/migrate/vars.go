@@ -0,0 +1,85 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// the fields in which the variables are referenced, by variable name
+type varReferences map[string][]varTarget
+
+// the fields of an object in which a variable is referenced
+type varTarget struct {
+	kind       string
+	name       string
+	namespace  string
+	fieldPaths []string
+}
+
+// finds the fields of the given objects whose value is a reference to one of the given variables (eg: `$(NAME)`).
+// Returns an error if a variable is referenced in a part of a value, since it cannot be converted into a replacement.
+func findVarReferences(objs []*unstructured.Unstructured, names []string) (varReferences, error) {
+	refs := varReferences{}
+	for _, obj := range objs {
+		paths := map[string][]string{}
+		var walk func(value interface{}, path []string) error
+		walk = func(value interface{}, path []string) error {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				keys := make([]string, 0, len(v))
+				for k := range v {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					if err := walk(v[k], append(path, k)); err != nil {
+						return err
+					}
+				}
+			case []interface{}:
+				for i, e := range v {
+					if err := walk(e, append(path, elementPath(e, i))); err != nil {
+						return err
+					}
+				}
+			case string:
+				for _, n := range names {
+					ref := "$(" + n + ")"
+					switch {
+					case v == ref:
+						paths[n] = append(paths[n], strings.Join(path, "."))
+					case strings.Contains(v, ref):
+						return fmt.Errorf("variable '%s' is referenced in a part of the value of '%s' in %s/%s", n, strings.Join(path, "."), obj.GetKind(), obj.GetName())
+					}
+				}
+			}
+			return nil
+		}
+		if err := walk(obj.Object, []string{}); err != nil {
+			return nil, err
+		}
+		for n, p := range paths {
+			refs[n] = append(refs[n], varTarget{
+				kind:       obj.GetKind(),
+				name:       obj.GetName(),
+				namespace:  obj.GetNamespace(),
+				fieldPaths: p,
+			})
+		}
+	}
+	return refs, nil
+}
+
+// returns the path of the element of a list: `[name=<name>]` if the element has a name, its index otherwise
+func elementPath(e interface{}, i int) string {
+	if m, ok := e.(map[string]interface{}); ok {
+		if name, ok := m["name"].(string); ok && name != "" && !strings.ContainsAny(name, ".[]=") {
+			return "[name=" + name + "]"
+		}
+	}
+	return strconv.Itoa(i)
+}
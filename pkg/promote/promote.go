@@ -0,0 +1,235 @@
+package promote
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Options of the promotion
+type Options struct {
+	// Images names of the images to copy from the source overlay (all the images of the source overlay
+	// if no image and no patch is selected)
+	Images []string
+	// Patches paths of the patches to copy from the source overlay, as declared in its `patches`
+	Patches []string
+}
+
+// Result of a promotion
+type Result struct {
+	// Source path of the source overlay (relative to the base directory)
+	Source string
+	// Target path of the target overlay (relative to the base directory)
+	Target string
+	// Files created or modified in the target overlay (relative to the base directory), sorted
+	Files []string
+	// Diffs the differences of the objects rendered by the target overlay, sorted by ID
+	Diffs []diff.ObjectDiff
+}
+
+// Component promotes the given component from the `from` overlay to the `to` overlay, by copying the selected
+// `images` entries and `patches` of the source overlay into the target overlay.
+// The overlays are looked up in the `overlays/<env>` and `<env>` directories of the component, in the `components` paths
+// (relative to the base directory).
+// The differences of the objects rendered by the target overlay are computed in memory, and the files are written
+// only when `write` is true.
+func Component(logger *log.Logger, afs afero.Afero, baseDir string, components []string, name, from, to string, opts Options, write bool) (*Result, error) {
+	compDir, err := lookupComponent(afs, baseDir, components, name)
+	if err != nil {
+		return nil, err
+	}
+	source, err := lookupOverlay(logger, afs, compDir, from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := lookupOverlay(logger, afs, compDir, to)
+	if err != nil {
+		return nil, err
+	}
+	if source == target {
+		return nil, fmt.Errorf("the source and the target overlays are the same: %s", source)
+	}
+	sourceKpath, _ := kustomizations.Lookup(logger, afs, source)
+	targetKpath, _ := kustomizations.Lookup(logger, afs, target)
+	src, err := parse(afs, sourceKpath)
+	if err != nil {
+		return nil, err
+	}
+	tgt, err := parse(afs, targetKpath)
+	if err != nil {
+		return nil, err
+	}
+
+	images := opts.Images
+	if len(images) == 0 && len(opts.Patches) == 0 {
+		if images, err = imageNames(src); err != nil {
+			return nil, err
+		}
+	}
+	for _, img := range images {
+		if err := copyEntry(src, tgt, "images", "name", img); err != nil {
+			return nil, err
+		}
+	}
+	// files to create or update (with absolute paths)
+	files := map[string][]byte{}
+	for _, p := range opts.Patches {
+		if err := copyEntry(src, tgt, "patches", "path", p); err != nil {
+			return nil, err
+		}
+		// the path is resolved against each overlay, since they may not be at the same depth in the component
+		sourcePatch, targetPatch := filepath.Join(source, p), filepath.Join(target, p)
+		if !kustomizations.Contains(target, targetPatch) {
+			// kustomize does not load the files outside of the overlay
+			return nil, fmt.Errorf("patch '%s' of the source overlay is outside of the target overlay", p)
+		}
+		if sourcePatch == targetPatch {
+			continue
+		}
+		data, err := afs.ReadFile(sourcePatch)
+		if err != nil {
+			return nil, err
+		}
+		files[targetPatch] = data
+	}
+	kdata, err := tgt.String()
+	if err != nil {
+		return nil, err
+	}
+	files[targetKpath] = []byte(kdata)
+	for path, data := range files {
+		if existing, err := afs.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+			delete(files, path)
+		}
+	}
+
+	// render the target overlay before and after the promotion
+	fsys, err := validation.NewInMemoryFS(logger, afs, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	rtarget, _ := filepath.Rel(baseDir, target)
+	before, err := render.Kustomize(fsys, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render overlay '%s' before the promotion: %w", rtarget, err)
+	}
+	for path, data := range files {
+		if err := fsys.MkdirAll(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+		if err := fsys.WriteFile(path, data); err != nil {
+			return nil, err
+		}
+	}
+	after, err := render.Kustomize(fsys, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render overlay '%s' after the promotion: %w", rtarget, err)
+	}
+	diffs, err := diff.Objects(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	rsource, _ := filepath.Rel(baseDir, source)
+	result := &Result{
+		Source: rsource,
+		Target: rtarget,
+		Files:  make([]string, 0, len(files)),
+		Diffs:  diffs,
+	}
+	for path, data := range files {
+		rpath, _ := filepath.Rel(baseDir, path)
+		result.Files = append(result.Files, rpath)
+		if !write {
+			continue
+		}
+		logger.Debug("writing file", "path", rpath)
+		if err := afs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := afs.WriteFile(path, data, 0644); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(result.Files)
+	return result, nil
+}
+
+// returns the directory of the component in the first of the `components` paths which contains it
+func lookupComponent(afs afero.Afero, baseDir string, components []string, name string) (string, error) {
+	for _, c := range components {
+		dir := filepath.Join(baseDir, c, name)
+		if exists, err := afs.DirExists(dir); err != nil {
+			return "", err
+		} else if exists {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("component not found: %s", name)
+}
+
+// returns the directory of the overlay of the component for the given environment
+func lookupOverlay(logger *log.Logger, afs afero.Afero, compDir, env string) (string, error) {
+	for _, dir := range []string{
+		filepath.Join(compDir, "overlays", env),
+		filepath.Join(compDir, env),
+	} {
+		if _, found := kustomizations.Lookup(logger, afs, dir); found {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("overlay '%s' not found in %s (expected a kustomization file in 'overlays/%s' or '%s')", env, compDir, env, env)
+}
+
+// parses the Kustomization file at the given path (comments are preserved)
+func parse(afs afero.Afero, kpath string) (*kyaml.RNode, error) {
+	data, err := afs.ReadFile(kpath)
+	if err != nil {
+		return nil, err
+	}
+	return kyaml.Parse(string(data))
+}
+
+// returns the names of the `images` of the given Kustomization
+func imageNames(kustomization *kyaml.RNode) ([]string, error) {
+	images, err := kustomization.Pipe(kyaml.Lookup("images"))
+	if err != nil || images == nil {
+		return nil, err
+	}
+	return images.ElementValues("name")
+}
+
+// copies the element of the `field` list whose `key` has the given value from the source Kustomization
+// to the target Kustomization, where it replaces the element with the same key (if any)
+func copyEntry(src, tgt *kyaml.RNode, field, key, value string) error {
+	entry, err := src.Pipe(kyaml.Lookup(field), kyaml.MatchElement(key, value))
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return fmt.Errorf("no entry with %s '%s' in the '%s' of the source overlay", key, value, field)
+	}
+	list, err := tgt.Pipe(kyaml.LookupCreate(kyaml.SequenceNode, field))
+	if err != nil {
+		return err
+	}
+	list.YNode().Style &^= kyaml.FlowStyle
+	for i, e := range list.YNode().Content {
+		if v, _ := kyaml.NewRNode(e).GetString(key); v == value {
+			list.YNode().Content[i] = entry.Copy().YNode()
+			return nil
+		}
+	}
+	list.YNode().Content = append(list.YNode().Content, entry.Copy().YNode())
+	return nil
+}
@@ -0,0 +1,278 @@
+package promote_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/diff"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/promote"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent(t *testing.T) {
+
+	logger := log.New(os.Stderr)
+
+	files := map[string]string{
+		"/path/to/components/cookie/base/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+`,
+		"/path/to/components/cookie/base/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/sandbox/cookie
+      - name: proxy
+        image: quay.io/sandbox/proxy
+`,
+		"/path/to/components/cookie/overlays/dev/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../../base
+images:
+- name: quay.io/sandbox/cookie
+  newTag: v1.2.0
+- name: quay.io/sandbox/proxy
+  newTag: v2.0.0
+patches:
+- path: replicas.yaml
+`,
+		"/path/to/components/cookie/overlays/dev/replicas.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  replicas: 2
+`,
+		"/path/to/components/cookie/overlays/stage/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: stage # the stage namespace
+resources:
+- ../../base
+images:
+- name: quay.io/sandbox/cookie
+  newTag: v1.1.0 # previous version
+`,
+	}
+
+	t.Run("all images", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		result, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "components/cookie/overlays/dev", result.Source)
+		assert.Equal(t, "components/cookie/overlays/stage", result.Target)
+		assert.Equal(t, []string{"components/cookie/overlays/stage/kustomization.yaml"}, result.Files)
+		require.Len(t, result.Diffs, 1)
+		assert.Equal(t, diff.ObjectDiff{
+			ID:     "Deployment/stage/cookie",
+			Status: diff.Modified,
+			Diff: `--- a/Deployment/stage/cookie
++++ b/Deployment/stage/cookie
+@@ -7,7 +7,7 @@
+   template:
+     spec:
+       containers:
+-      - image: quay.io/sandbox/cookie:v1.1.0
++      - image: quay.io/sandbox/cookie:v1.2.0
+         name: cookie
+-      - image: quay.io/sandbox/proxy
++      - image: quay.io/sandbox/proxy:v2.0.0
+         name: proxy
+`,
+		}, result.Diffs[0])
+		data, err := afs.ReadFile("/path/to/components/cookie/overlays/stage/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: stage # the stage namespace
+resources:
+- ../../base
+images:
+- name: quay.io/sandbox/cookie
+  newTag: v1.2.0
+- name: quay.io/sandbox/proxy
+  newTag: v2.0.0
+`, string(data))
+	})
+
+	t.Run("selected image and patch", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		result, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{
+			Images:  []string{"quay.io/sandbox/proxy"},
+			Patches: []string{"replicas.yaml"},
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"components/cookie/overlays/stage/kustomization.yaml",
+			"components/cookie/overlays/stage/replicas.yaml",
+		}, result.Files)
+		require.Len(t, result.Diffs, 1)
+		assert.Contains(t, result.Diffs[0].Diff, "+  replicas: 2\n")
+		assert.Contains(t, result.Diffs[0].Diff, "+      - image: quay.io/sandbox/proxy:v2.0.0\n")
+		data, err := afs.ReadFile("/path/to/components/cookie/overlays/stage/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: stage # the stage namespace
+resources:
+- ../../base
+images:
+- name: quay.io/sandbox/cookie
+  newTag: v1.1.0 # previous version
+- name: quay.io/sandbox/proxy
+  newTag: v2.0.0
+patches:
+- path: replicas.yaml
+`, string(data))
+		data, err = afs.ReadFile("/path/to/components/cookie/overlays/stage/replicas.yaml")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "replicas: 2")
+	})
+
+	t.Run("dry-run", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+
+		// when
+		result, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{
+			Patches: []string{"replicas.yaml"},
+		}, false)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, result.Files, 2)
+		assert.Len(t, result.Diffs, 1)
+		exists, err := afs.Exists("/path/to/components/cookie/overlays/stage/replicas.yaml")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("already promoted", func(t *testing.T) {
+		// given
+		afs := test.NewFS(t, files)
+		_, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{}, true)
+		require.NoError(t, err)
+
+		// when
+		result, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, result.Files)
+		assert.Empty(t, result.Diffs)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("unknown component", func(t *testing.T) {
+			// given
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "pasta", "dev", "stage", promote.Options{}, true)
+
+			// then
+			require.EqualError(t, err, "component not found: pasta")
+		})
+
+		t.Run("unknown overlay", func(t *testing.T) {
+			// given
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "prod", promote.Options{}, true)
+
+			// then
+			require.EqualError(t, err, "overlay 'prod' not found in /path/to/components/cookie (expected a kustomization file in 'overlays/prod' or 'prod')")
+		})
+
+		t.Run("unknown image", func(t *testing.T) {
+			// given
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{
+				Images: []string{"quay.io/sandbox/pasta"},
+			}, true)
+
+			// then
+			require.EqualError(t, err, "no entry with name 'quay.io/sandbox/pasta' in the 'images' of the source overlay")
+		})
+
+		t.Run("patch shared by the overlays", func(t *testing.T) {
+			// given
+			afs := test.NewFS(t, files, map[string]string{
+				"/path/to/components/cookie/overlays/dev/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../../base
+patches:
+- path: ./../patches/replicas.yaml
+`,
+				"/path/to/components/cookie/overlays/patches/replicas.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  replicas: 2
+`,
+			})
+
+			// when
+			_, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "stage", promote.Options{
+				Patches: []string{"./../patches/replicas.yaml"},
+			}, true)
+
+			// then
+			require.EqualError(t, err, "patch './../patches/replicas.yaml' of the source overlay is outside of the target overlay")
+		})
+
+		t.Run("patch resolved outside of the target overlay", func(t *testing.T) {
+			// given
+			afs := test.NewFS(t, files, map[string]string{
+				"/path/to/components/cookie/overlays/dev/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../../base
+patches:
+- path: patches/../../dev/replicas.yaml
+`,
+				// not in the `overlays` directory
+				"/path/to/components/cookie/prod/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../base
+`,
+			})
+
+			// when
+			_, err := promote.Component(logger, afs, "/path/to", []string{"components"}, "cookie", "dev", "prod", promote.Options{
+				Patches: []string{"patches/../../dev/replicas.yaml"},
+			}, true)
+
+			// then
+			require.EqualError(t, err, "patch 'patches/../../dev/replicas.yaml' of the source overlay is outside of the target overlay")
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/charmbracelet/log"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Directory reads the manifests in the YAML and JSON files of the given directory (and its subdirectories if `recurse` is set),
+// taking into account the `include` and `exclude` glob patterns of the Application source.
+func Directory(logger *log.Logger, fsys kfsys.FileSystem, path string, opts *argocdv1alpha1.ApplicationSourceDirectory) ([]*unstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &argocdv1alpha1.ApplicationSourceDirectory{}
+	}
+	objs := []*unstructured.Unstructured{}
+	err := fsys.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != path && !opts.Recurse {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		if !matches(opts.Include, rel, true) || matches(opts.Exclude, rel, false) {
+			logger.Debug("skipping file", "path", p)
+			return nil
+		}
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		o, err := kube.SplitYAML(data)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, o...)
+		return nil
+	})
+	return objs, err
+}
+
+// returns true if the path matches the given glob pattern(s) (eg: `*.yaml` or `{config.yaml,*.json}`),
+// or returns the default value if no pattern was specified
+func matches(pattern, path string, defaultValue bool) bool {
+	if pattern == "" {
+		return defaultValue
+	}
+	patterns := []string{pattern}
+	if strings.HasPrefix(pattern, "{") && strings.HasSuffix(pattern, "}") {
+		patterns = strings.Split(strings.TrimSuffix(strings.TrimPrefix(pattern, "{"), "}"), ",")
+	}
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, path); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, filepath.Base(path)); ok {
+			return true
+		}
+	}
+	return false
+}
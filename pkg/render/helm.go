@@ -0,0 +1,125 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"github.com/charmbracelet/log"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Helm runs `helm template` on the chart in the path of the given Application source, with the release name, namespace,
+// value files, values and parameters of the source (as Argo CD would do).
+// The chart and the value files are copied from the given filesystem into a temporary directory, since the `helm` binary
+// can only read from the local disk.
+func Helm(logger *log.Logger, fsys kfsys.FileSystem, baseDir string, app *argocdv1alpha1.Application, source argocdv1alpha1.ApplicationSource) ([]*unstructured.Unstructured, error) {
+	tmpDir, err := os.MkdirTemp("", "sandbox-argocd-helm-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(baseDir, source.Path)
+	chartDir := filepath.Join(tmpDir, "chart")
+	if err := copyToDisk(fsys, path, chartDir); err != nil {
+		return nil, err
+	}
+
+	opts := source.Helm
+	if opts == nil {
+		opts = &argocdv1alpha1.ApplicationSourceHelm{}
+	}
+	releaseName := app.Name
+	if opts.ReleaseName != "" {
+		releaseName = opts.ReleaseName
+	}
+	args := []string{"template", releaseName, chartDir, "--include-crds"}
+	if app.Spec.Destination.Namespace != "" {
+		args = append(args, "--namespace", app.Spec.Destination.Namespace)
+	}
+	if opts.SkipCrds {
+		args = append(args, "--skip-crds")
+	}
+	for i, vf := range opts.ValueFiles {
+		if strings.Contains(vf, "://") {
+			args = append(args, "--values", vf)
+			continue
+		}
+		// value files are relative to the chart, or to the root of the repository when using a `$ref` of a multi-source Application
+		p := filepath.Join(path, vf)
+		if strings.HasPrefix(vf, "$") {
+			_, rel, _ := strings.Cut(vf, "/")
+			p = filepath.Join(baseDir, rel)
+		}
+		if !fsys.Exists(p) {
+			if opts.IgnoreMissingValueFiles {
+				logger.Debug("ignoring missing value file", "path", p)
+				continue
+			}
+			return nil, fmt.Errorf("value file does not exist: %s", vf)
+		}
+		data, err := fsys.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		f := filepath.Join(tmpDir, fmt.Sprintf("values-%d.yaml", i))
+		if err := os.WriteFile(f, data, 0600); err != nil {
+			return nil, err
+		}
+		args = append(args, "--values", f)
+	}
+	if !opts.ValuesIsEmpty() {
+		f := filepath.Join(tmpDir, "values.yaml")
+		if err := os.WriteFile(f, opts.ValuesYAML(), 0600); err != nil {
+			return nil, err
+		}
+		args = append(args, "--values", f)
+	}
+	for _, p := range opts.Parameters {
+		if p.ForceString {
+			args = append(args, "--set-string", fmt.Sprintf("%s=%s", p.Name, p.Value))
+		} else {
+			args = append(args, "--set", fmt.Sprintf("%s=%s", p.Name, p.Value))
+		}
+	}
+	for _, p := range opts.FileParameters {
+		args = append(args, "--set-file", fmt.Sprintf("%s=%s", p.Name, filepath.Join(chartDir, p.Path)))
+	}
+
+	logger.Debug("running helm", "args", strings.Join(args, " "))
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command("helm", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run 'helm template' on %s: %w: %s", source.Path, err, strings.TrimSpace(stderr.String()))
+	}
+	return kube.SplitYAML(stdout.Bytes())
+}
+
+// copies the contents of the given directory of the filesystem into the target directory on the local disk
+func copyToDisk(fsys kfsys.FileSystem, dir, target string) error {
+	return fsys.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(target, rel), 0700)
+		}
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(target, rel), data, 0600)
+	})
+}
@@ -0,0 +1,250 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// Kustomize runs `kustomize build` on the given path and returns the resulting objects
+func Kustomize(fsys kfsys.FileSystem, path string) ([]*unstructured.Unstructured, error) {
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	m, err := k.Run(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]*unstructured.Unstructured, 0, m.Size())
+	for _, r := range m.Resources() {
+		data, err := r.Map()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, &unstructured.Unstructured{Object: data})
+	}
+	return objs, nil
+}
+
+// KustomizeWithOptions runs `kustomize build` on the given path after applying the Argo CD-specific options
+// of the Application source on the Kustomization file, the same way as the Argo CD repo-server does with `kustomize edit`.
+// The Kustomization file is restored after the build.
+func KustomizeWithOptions(logger *log.Logger, fsys kfsys.FileSystem, path string, opts *argocdv1alpha1.ApplicationSourceKustomize) ([]*unstructured.Unstructured, error) {
+	if opts == nil {
+		return Kustomize(fsys, path)
+	}
+	return kustomizeWithEdit(logger, fsys, path, func(kobj *types.Kustomization) error {
+		return applyKustomizeOptions(fsys, path, kobj, opts)
+	})
+}
+
+// annotation with the origin of the objects rendered by `KustomizeWithOrigins`
+const originAnnotation = "config.kubernetes.io/origin"
+
+// KustomizeWithOrigins runs `kustomize build` on the given path with the `originAnnotations` build metadata,
+// so that the origin of each object can be retrieved with `Origin`.
+// The Kustomization file is restored after the build.
+func KustomizeWithOrigins(logger *log.Logger, fsys kfsys.FileSystem, path string) ([]*unstructured.Unstructured, error) {
+	return kustomizeWithEdit(logger, fsys, path, func(kobj *types.Kustomization) error {
+		kobj.BuildMetadata = append(kobj.BuildMetadata, types.OriginAnnotations)
+		return nil
+	})
+}
+
+// Origin returns the path of the file in which the given object (rendered with `KustomizeWithOrigins`) was declared,
+// or the path of the Kustomization file in which its generator was configured.
+// The path is relative to the kustomization directory on which `kustomize build` was run.
+func Origin(obj *unstructured.Unstructured) (string, bool) {
+	annotation, found := obj.GetAnnotations()[originAnnotation]
+	if !found {
+		return "", false
+	}
+	origin := struct {
+		Path         string `json:"path"`
+		ConfiguredIn string `json:"configuredIn"`
+	}{}
+	if err := yaml.Unmarshal([]byte(annotation), &origin); err != nil {
+		return "", false
+	}
+	if origin.Path != "" {
+		return origin.Path, true
+	}
+	return origin.ConfiguredIn, origin.ConfiguredIn != ""
+}
+
+// runs `kustomize build` on the given path after editing the Kustomization file, which is restored after the build
+func kustomizeWithEdit(logger *log.Logger, fsys kfsys.FileSystem, path string, edit func(*types.Kustomization) error) ([]*unstructured.Unstructured, error) {
+	kpath, found := kustomizations.LookupFS(logger, fsys, path)
+	if !found {
+		return nil, fmt.Errorf("%s does not contain a 'kustomization.yaml' file", path)
+	}
+	original, err := fsys.ReadFile(kpath)
+	if err != nil {
+		return nil, err
+	}
+	kobj := &types.Kustomization{}
+	if err := kobj.Unmarshal(original); err != nil {
+		return nil, err
+	}
+	if err := edit(kobj); err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(kobj)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("edited Kustomization", "path", kpath)
+	if err := fsys.WriteFile(kpath, data); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := fsys.WriteFile(kpath, original); err != nil {
+			logger.Error("failed to restore Kustomization", "path", kpath, "err", err)
+		}
+	}()
+	return Kustomize(fsys, path)
+}
+
+func applyKustomizeOptions(fsys kfsys.FileSystem, path string, kobj *types.Kustomization, opts *argocdv1alpha1.ApplicationSourceKustomize) error {
+	if opts.NamePrefix != "" {
+		kobj.NamePrefix = opts.NamePrefix
+	}
+	if opts.NameSuffix != "" {
+		kobj.NameSuffix = opts.NameSuffix
+	}
+	for _, i := range opts.Images {
+		setImage(kobj, parseImage(string(i)))
+	}
+	if len(opts.CommonLabels) > 0 {
+		if opts.LabelWithoutSelector {
+			kobj.Labels = append(kobj.Labels, types.Label{
+				Pairs:            opts.CommonLabels,
+				IncludeSelectors: false,
+			})
+		} else {
+			if kobj.CommonLabels == nil { //nolint:staticcheck
+				kobj.CommonLabels = map[string]string{} //nolint:staticcheck
+			}
+			if err := addPairs(kobj.CommonLabels, opts.CommonLabels, opts.ForceCommonLabels, "label"); err != nil { //nolint:staticcheck
+				return err
+			}
+		}
+	}
+	if len(opts.CommonAnnotations) > 0 {
+		if kobj.CommonAnnotations == nil {
+			kobj.CommonAnnotations = map[string]string{}
+		}
+		if err := addPairs(kobj.CommonAnnotations, opts.CommonAnnotations, opts.ForceCommonAnnotations, "annotation"); err != nil {
+			return err
+		}
+	}
+	if opts.Namespace != "" {
+		kobj.Namespace = opts.Namespace
+	}
+	for _, r := range opts.Replicas {
+		count, err := r.GetIntCount()
+		if err != nil {
+			return err
+		}
+		setReplicas(kobj, types.Replica{
+			Name:  r.Name,
+			Count: int64(count),
+		})
+	}
+	for _, p := range opts.Patches {
+		// Argo CD and Kustomize patches have the same structure
+		data, err := yaml.Marshal(p)
+		if err != nil {
+			return err
+		}
+		patch := types.Patch{}
+		if err := yaml.Unmarshal(data, &patch); err != nil {
+			return err
+		}
+		if patch.Path != "" && !fsys.Exists(filepath.Join(path, patch.Path)) {
+			return fmt.Errorf("patch does not exist: %s", patch.Path)
+		}
+		kobj.Patches = append(kobj.Patches, patch)
+	}
+	for _, c := range opts.Components {
+		if !kustomizations.IsRemote(c) && !fsys.Exists(filepath.Join(path, c)) {
+			return fmt.Errorf("component does not exist: %s", c)
+		}
+		kobj.Components = append(kobj.Components, c)
+	}
+	return nil
+}
+
+// replaces the replicas with the same name in the Kustomization, or adds it
+// (same behaviour as `kustomize edit set replicas`)
+func setReplicas(kobj *types.Kustomization, replicas types.Replica) {
+	for i, existing := range kobj.Replicas {
+		if existing.Name == replicas.Name {
+			kobj.Replicas[i] = replicas
+			return
+		}
+	}
+	kobj.Replicas = append(kobj.Replicas, replicas)
+}
+
+// adds the given key/value pairs into the target map, unless the key already exists and `force` is false
+// (same behaviour as `kustomize edit add label|annotation`)
+func addPairs(target, pairs map[string]string, force bool, kind string) error {
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, exists := target[k]; exists && !force {
+			return fmt.Errorf("%s %s already in kustomization file", kind, k)
+		}
+		target[k] = pairs[k]
+	}
+	return nil
+}
+
+// replaces the image with the same name in the Kustomization, or adds it
+// (same behaviour as `kustomize edit set image`)
+func setImage(kobj *types.Kustomization, img types.Image) {
+	for i, existing := range kobj.Images {
+		if existing.Name == img.Name {
+			kobj.Images[i] = img
+			return
+		}
+	}
+	kobj.Images = append(kobj.Images, img)
+}
+
+// parses an image override in the `[<name>=]<new_name>[:<tag>|@<digest>]` format
+func parseImage(s string) types.Image {
+	name, value, renamed := strings.Cut(s, "=")
+	if !renamed {
+		value = s
+	}
+	img := types.Image{}
+	newName := value
+	if i := strings.Index(value, "@"); i >= 0 {
+		newName, img.Digest = value[:i], value[i+1:]
+	} else if i := strings.LastIndex(value, ":"); i > strings.LastIndex(value, "/") {
+		newName, img.NewTag = value[:i], value[i+1:]
+	}
+	if !renamed {
+		img.Name = newName
+	} else {
+		img.Name = name
+		if newName != name {
+			img.NewName = newName
+		}
+	}
+	return img
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// WriteYAML writes the given objects as a stream of YAML documents
+func WriteYAML(out io.Writer, objs []*unstructured.Unstructured) error {
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := out.Write([]byte("---\n")); err != nil {
+				return err
+			}
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFiles writes each object in a separate file in the given directory (see `Filename`).
+// Returns the paths of the files
+func WriteFiles(afs afero.Afero, dir string, objs []*unstructured.Unstructured) ([]string, error) {
+	paths := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		p := filepath.Join(dir, Filename(obj))
+		if err := afs.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return nil, err
+		}
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		if err := afs.WriteFile(p, data, 0644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// Filename returns the name of the file for the given object, which is unique for each object:
+// `<kind>[.<group>]_[<namespace>_]<name>.yaml` (eg: `deployment.apps_cookie_cookie.yaml` or `namespace_cookie.yaml`).
+// Underscores are not allowed in the names of the objects, so they can't be confused with the separators.
+func Filename(obj *unstructured.Unstructured) string {
+	kind := strings.ToLower(obj.GetKind())
+	if group := obj.GroupVersionKind().Group; group != "" {
+		kind += "." + group
+	}
+	if obj.GetNamespace() != "" {
+		return fmt.Sprintf("%s_%s_%s.yaml", kind, obj.GetNamespace(), obj.GetName())
+	}
+	return fmt.Sprintf("%s_%s.yaml", kind, obj.GetName())
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Application renders the manifests of the source(s) of the given Application, using the local copy of the repository
+// (in the base directory of the given filesystem) instead of the `repoURL` and `targetRevision` of the source(s).
+// Argo CD-specific source options (Kustomize and Helm) are applied as Argo CD would do.
+func Application(logger *log.Logger, fsys kfsys.FileSystem, baseDir string, app *argocdv1alpha1.Application) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+	for _, source := range app.Spec.GetSources() {
+		if source.Path == "" && source.Ref != "" {
+			// source only used as a reference to other files (eg: Helm value files)
+			continue
+		}
+		o, err := Source(logger, fsys, baseDir, app, source)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, o...)
+	}
+	return objs, nil
+}
+
+// Source renders the manifests of a single source of the given Application
+func Source(logger *log.Logger, fsys kfsys.FileSystem, baseDir string, app *argocdv1alpha1.Application, source argocdv1alpha1.ApplicationSource) ([]*unstructured.Unstructured, error) {
+	if source.Chart != "" {
+		return nil, fmt.Errorf("rendering of Helm chart from a remote repository is not supported: %s", source.Chart)
+	}
+	if source.Plugin != nil {
+		return nil, fmt.Errorf("rendering with a Config Management Plugin is not supported: %s", source.Path)
+	}
+	path := filepath.Join(baseDir, source.Path)
+	if !fsys.IsDir(path) {
+		return nil, fmt.Errorf("%s is not valid", source.Path)
+	}
+	_, kustomization := kustomizations.LookupFS(logger, fsys, path)
+	switch {
+	case source.Helm != nil || fsys.Exists(filepath.Join(path, "Chart.yaml")):
+		logger.Debug("rendering Helm chart", "path", path)
+		return Helm(logger, fsys, baseDir, app, source)
+	case source.Kustomize != nil || kustomization:
+		logger.Debug("rendering Kustomization", "path", path)
+		return KustomizeWithOptions(logger, fsys, path, source.Kustomize)
+	default:
+		logger.Debug("rendering directory", "path", path)
+		return Directory(logger, fsys, path, source.Directory)
+	}
+}
@@ -0,0 +1,308 @@
+package render_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRenderApplication(t *testing.T) {
+
+	logger := log.New(os.Stdout)
+
+	t.Run("kustomize", func(t *testing.T) {
+
+		t.Run("without options", func(t *testing.T) {
+			// given
+			fsys := newFS(t)
+			app := newApplication(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/cookie`)
+
+			// when
+			objs, err := render.Application(logger, fsys, "/path/to", app)
+
+			// then
+			require.NoError(t, err)
+			require.Len(t, objs, 2)
+			assert.Equal(t, "cookie", objs[0].GetName())
+			assert.Equal(t, "cookie", objs[1].GetName())
+			assert.Equal(t, []string{"nginx"}, images(t, objs[1]))
+		})
+
+		t.Run("with options", func(t *testing.T) {
+			// given
+			fsys := newFS(t)
+			app := newApplication(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/cookie
+    kustomize:
+      namePrefix: yummy-
+      nameSuffix: -v2
+      images:
+      - nginx=quay.io/nginx:1.27
+      commonLabels:
+        app: cookie`)
+
+			// when
+			objs, err := render.Application(logger, fsys, "/path/to", app)
+
+			// then
+			require.NoError(t, err)
+			require.Len(t, objs, 2)
+			assert.Equal(t, "yummy-cookie-v2", objs[0].GetName())
+			assert.Equal(t, map[string]string{"app": "cookie"}, objs[0].GetLabels())
+			assert.Equal(t, "yummy-cookie-v2", objs[1].GetName())
+			assert.Equal(t, []string{"quay.io/nginx:1.27"}, images(t, objs[1]))
+			// kustomization file was restored
+			data, err := fsys.ReadFile("/path/to/components/cookie/kustomization.yaml")
+			require.NoError(t, err)
+			assert.Equal(t, kustomization, string(data))
+		})
+
+		t.Run("with image digest", func(t *testing.T) {
+			// given
+			fsys := newFS(t)
+			app := newApplication(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/cookie
+    kustomize:
+      images:
+      - nginx@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3`)
+
+			// when
+			objs, err := render.Application(logger, fsys, "/path/to", app)
+
+			// then
+			require.NoError(t, err)
+			require.Len(t, objs, 2)
+			assert.Equal(t, []string{"nginx@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"}, images(t, objs[1]))
+		})
+
+		t.Run("with conflicting label", func(t *testing.T) {
+			// given
+			fsys := newFS(t)
+			err := fsys.WriteFile("/path/to/components/cookie/kustomization.yaml", []byte(kustomization+`
+commonLabels:
+  app: pasta`))
+			require.NoError(t, err)
+			app := newApplication(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/cookie
+    kustomize:
+      commonLabels:
+        app: cookie`)
+
+			// when
+			_, err = render.Application(logger, fsys, "/path/to", app)
+
+			// then
+			require.EqualError(t, err, "label app already in kustomization file")
+		})
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		// given
+		fsys := newFS(t)
+		app := newApplication(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/pasta
+    directory:
+      exclude: secret.yaml`)
+
+		// when
+		objs, err := render.Application(logger, fsys, "/path/to", app)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 1)
+		assert.Equal(t, "ConfigMap", objs[0].GetKind())
+		assert.Equal(t, "pasta", objs[0].GetName())
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		// given
+		fsys := newFS(t)
+		app := newApplication(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-pizza
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/pizza`)
+
+		// when
+		_, err := render.Application(logger, fsys, "/path/to", app)
+
+		// then
+		require.EqualError(t, err, "components/pizza is not valid")
+	})
+}
+
+func TestWriteYAML(t *testing.T) {
+	// given
+	fsys := newFS(t)
+	objs, err := render.Kustomize(fsys, "/path/to/components/cookie")
+	require.NoError(t, err)
+	buffy := &bytes.Buffer{}
+
+	// when
+	err = render.WriteYAML(buffy, objs)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+data:
+  cookie: yummy
+kind: ConfigMap
+metadata:
+  name: cookie
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx
+        name: cookie
+`, buffy.String())
+}
+
+func TestWriteFiles(t *testing.T) {
+	// given
+	afs := test.NewFS(t)
+	objs := []*unstructured.Unstructured{
+		test.NewObject("v1", "Namespace", "", "cookie", nil),
+		test.NewObject("v1", "Service", "cookie", "cookie", nil),
+		test.NewObject("v1", "Service", "pasta", "cookie", nil),
+		test.NewObject("serving.knative.dev/v1", "Service", "cookie", "cookie", nil),
+	}
+
+	// when
+	paths, err := render.WriteFiles(afs, "/path/to/output", objs)
+
+	// then
+	require.NoError(t, err)
+	// objects with the same kind and name in different groups or namespaces are written in different files
+	assert.Equal(t, []string{
+		"/path/to/output/namespace_cookie.yaml",
+		"/path/to/output/service_cookie_cookie.yaml",
+		"/path/to/output/service_pasta_cookie.yaml",
+		"/path/to/output/service.serving.knative.dev_cookie_cookie.yaml",
+	}, paths)
+	data, err := afs.ReadFile("/path/to/output/service.serving.knative.dev_cookie_cookie.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: cookie
+  namespace: cookie
+`, string(data))
+}
+
+const kustomization = `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- configmap.yaml
+- deployment.yaml
+`
+
+func newFS(t *testing.T) kfsys.FileSystem {
+	fsys := kfsys.MakeFsInMemory()
+	for path, data := range map[string]string{
+		"/path/to/components/cookie/kustomization.yaml": kustomization,
+		"/path/to/components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+data:
+  cookie: yummy`,
+		"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: nginx`,
+		"/path/to/components/pasta/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta
+data:
+  pasta: yummy`,
+		"/path/to/components/pasta/secret.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: pasta`,
+	} {
+		err := fsys.WriteFile(path, []byte(data))
+		require.NoError(t, err)
+	}
+	return fsys
+}
+
+func newApplication(t *testing.T, data string) *argocdv1alpha1.Application {
+	app := &argocdv1alpha1.Application{}
+	err := yaml.Unmarshal([]byte(data), app)
+	require.NoError(t, err)
+	return app
+}
+
+func images(t *testing.T, obj *unstructured.Unstructured) []string {
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	result := []string{}
+	for _, c := range containers {
+		result = append(result, c.(map[string]interface{})["image"].(string))
+	}
+	return result
+}
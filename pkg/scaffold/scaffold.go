@@ -0,0 +1,317 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	iofs "io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// Built-in templates. Each template is a directory which contains:
+// - a `component` directory, whose files are rendered in the new component directory (the `.tmpl` suffix is removed),
+// - an optional `application.yaml.tmpl` and `applicationset.yaml.tmpl` files, which override the default
+// Application and ApplicationSet templates,
+// - an optional `values.yaml` file, with the default values of the template.
+//
+//go:embed templates
+var builtins embed.FS
+
+// DefaultTemplate name of the template used when none is specified
+const DefaultTemplate = "kustomize"
+
+// DefaultTemplatesDir default directory of the repo-local templates (relative to the base directory)
+const DefaultTemplatesDir = ".templates"
+
+const (
+	componentDir           = "component"
+	applicationTemplate    = "application.yaml.tmpl"
+	applicationSetTemplate = "applicationset.yaml.tmpl"
+	valuesFile             = "values.yaml"
+	templateSuffix         = ".tmpl"
+)
+
+var componentName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Options of the new component
+type Options struct {
+	// Template name of the template to use (a repo-local template in `TemplatesDir` or a built-in template)
+	Template string
+	// TemplatesDir directory of the repo-local templates (relative to the base directory)
+	TemplatesDir string
+	// ApplicationSet when true, an ApplicationSet is created instead of an Application
+	ApplicationSet bool
+	// Values used in the templates, in addition to (or overriding) the default values:
+	// `Name`, `Namespace` (defaults to the name), `Path` (path of the component relative to the base directory),
+	// `Project` (defaults to `default`), `RepoURL` and `TargetRevision` (default to the most common values
+	// in the existing Applications and ApplicationSets)
+	Values map[string]string
+}
+
+// NewComponent creates a new component from a template in the `components` directory, with an Application
+// (or ApplicationSet) in the `apps` directory (both relative to the base directory), and adds them to the `resources`
+// of the Kustomization files in their parent directories, if any.
+// Returns the paths (relative to the base directory) of the created and modified files, sorted.
+func NewComponent(logger *log.Logger, afs afero.Afero, baseDir, apps, components, name string, opts Options) ([]string, error) {
+	if len(name) > 63 || !componentName.MatchString(name) {
+		return nil, fmt.Errorf("invalid component name: '%s' (expected at most 63 lowercase alphanumeric characters or '-', "+
+			"starting and ending with an alphanumeric character)", name)
+	}
+	compDir := filepath.Join(baseDir, components, name)
+	if exists, err := afs.Exists(compDir); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("component already exists: %s", filepath.Join(components, name))
+	}
+	appTemplate, prefix := applicationTemplate, "app-"
+	if opts.ApplicationSet {
+		appTemplate, prefix = applicationSetTemplate, "appset-"
+	}
+	appPath := filepath.Join(baseDir, apps, prefix+name+".yaml")
+	if exists, err := afs.Exists(appPath); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("application already exists: %s", filepath.Join(apps, prefix+name+".yaml"))
+	}
+
+	tmpl, err := lookupTemplate(logger, afs, baseDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	values, err := newValues(logger, afs, baseDir, apps, components, name, tmpl, opts.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	// render all the files before writing them, so that nothing is created if a template fails
+	rendered := map[string][]byte{}
+	if err := iofs.WalkDir(tmpl, componentDir, func(p string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(componentDir, filepath.FromSlash(p))
+		if err != nil {
+			return err
+		}
+		rendered[filepath.Join(compDir, strings.TrimSuffix(rel, templateSuffix))], err = renderTemplate(tmpl, p, values)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	// Application or ApplicationSet, from the template or from the built-in defaults
+	appFS := tmpl
+	if _, err := iofs.Stat(tmpl, appTemplate); err != nil {
+		if appFS, err = iofs.Sub(builtins, "templates"); err != nil {
+			return nil, err
+		}
+	}
+	if rendered[appPath], err = renderTemplate(appFS, appTemplate, values); err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(rendered))
+	for target, data := range rendered {
+		if err := afs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		if err := afs.WriteFile(target, data, 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, target)
+	}
+
+	// register the new component and Application in the Kustomization files of their parent directories
+	for _, p := range []string{compDir, appPath} {
+		kpath, found := kustomizations.Lookup(logger, afs, filepath.Dir(p))
+		if !found {
+			continue
+		}
+		if modified, err := kustomizations.AddResources(afs, kpath, filepath.Base(p)); err != nil {
+			return nil, err
+		} else if modified {
+			logger.Debug("added resource to kustomization", "path", kpath, "resource", filepath.Base(p))
+			files = append(files, kpath)
+		}
+	}
+
+	result := make([]string, 0, len(files))
+	for _, f := range files {
+		rel, err := filepath.Rel(baseDir, f)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rel)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Templates returns the names of the repo-local templates (in the given directory, relative to the base directory)
+// and of the built-in templates, sorted
+func Templates(afs afero.Afero, baseDir, templatesDir string) ([]string, error) {
+	names := map[string]bool{}
+	entries, err := iofs.ReadDir(builtins, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			names[e.Name()] = true
+		}
+	}
+	if templatesDir != "" {
+		dir := filepath.Join(baseDir, templatesDir)
+		if exists, err := afs.DirExists(dir); err != nil {
+			return nil, err
+		} else if exists {
+			infos, err := afs.ReadDir(dir)
+			if err != nil {
+				return nil, err
+			}
+			for _, i := range infos {
+				if i.IsDir() {
+					names[i.Name()] = true
+				}
+			}
+		}
+	}
+	result := make([]string, 0, len(names))
+	for n := range names {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// returns the repo-local template with the given name if it exists, or the built-in template otherwise
+func lookupTemplate(logger *log.Logger, afs afero.Afero, baseDir string, opts Options) (iofs.FS, error) {
+	name := opts.Template
+	if name == "" {
+		name = DefaultTemplate
+	}
+	if opts.TemplatesDir != "" {
+		dir := filepath.Join(baseDir, opts.TemplatesDir, name)
+		if exists, err := afs.DirExists(dir); err != nil {
+			return nil, err
+		} else if exists {
+			logger.Debug("using repo-local template", "path", dir)
+			return afero.NewIOFS(afero.NewBasePathFs(afs.Fs, dir)), nil
+		}
+	}
+	if info, err := iofs.Stat(builtins, path.Join("templates", name)); err == nil && info.IsDir() {
+		logger.Debug("using built-in template", "name", name)
+		return iofs.Sub(builtins, path.Join("templates", name))
+	}
+	available, err := Templates(afs, baseDir, opts.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unknown template: '%s' (available: %s)", name, strings.Join(available, ", "))
+}
+
+// returns the values used in the templates: the default values, then the values of the template,
+// then the given values
+func newValues(logger *log.Logger, afs afero.Afero, baseDir, apps, components, name string, tmpl iofs.FS, overrides map[string]string) (map[string]string, error) {
+	repoURL, revision, err := commonSource(logger, afs, filepath.Join(baseDir, apps))
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{
+		"Name":           name,
+		"Namespace":      name,
+		"Path":           filepath.ToSlash(filepath.Join(components, name)),
+		"Project":        "default",
+		"TargetRevision": "HEAD",
+	}
+	if repoURL != "" {
+		values["RepoURL"] = repoURL
+	}
+	if revision != "" {
+		values["TargetRevision"] = revision
+	}
+	if data, err := iofs.ReadFile(tmpl, valuesFile); err == nil {
+		defaults := map[string]string{}
+		if err := yaml.UnmarshalStrict(data, &defaults); err != nil {
+			return nil, fmt.Errorf("invalid template values in %s: %w", valuesFile, err)
+		}
+		for k, v := range defaults {
+			values[k] = v
+		}
+	}
+	for k, v := range overrides {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// returns the most common repository URL and target revision of the sources of the Applications and ApplicationSets
+// in the given directory (or empty strings if there is none)
+func commonSource(logger *log.Logger, afs afero.Afero, dir string) (string, string, error) {
+	if exists, err := afs.DirExists(dir); err != nil || !exists {
+		return "", "", err
+	}
+	apps, appsets, err := applications.ListApplications(logger, afs, dir)
+	if err != nil {
+		return "", "", err
+	}
+	repoURLs := map[string]int{}
+	revisions := map[string]int{}
+	for _, app := range apps {
+		for _, s := range app.Spec.GetSources() {
+			repoURLs[s.RepoURL]++
+			revisions[s.TargetRevision]++
+		}
+	}
+	for _, appset := range appsets {
+		for _, s := range appset.Spec.Template.Spec.GetSources() {
+			repoURLs[s.RepoURL]++
+			revisions[s.TargetRevision]++
+		}
+	}
+	return mostCommon(repoURLs), mostCommon(revisions), nil
+}
+
+// returns the non-empty and non-templated value with the most occurrences (the first one in alphabetical order if equal)
+func mostCommon(counts map[string]int) string {
+	result, count := "", 0
+	for v, c := range counts {
+		if v == "" || applications.IsTemplated(v) {
+			continue
+		}
+		if c > count || (c == count && v < result) {
+			result, count = v, c
+		}
+	}
+	return result
+}
+
+// renders the template at the given path of the given filesystem. All the values used in the template must be defined.
+func renderTemplate(fsys iofs.FS, p string, values map[string]string) ([]byte, error) {
+	data, err := iofs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, err
+	}
+	t, err := template.New(path.Base(p)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", p, err)
+	}
+	out := &bytes.Buffer{}
+	if err := t.Execute(out, values); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", p, err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,247 @@
+package scaffold_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/format"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/scaffold"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewComponent(t *testing.T) {
+
+	files := map[string]string{
+		"/path/to/apps/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- app-cookie.yaml
+`,
+		"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    namespace: cookie
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: main
+`,
+		"/path/to/components/cookie/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: cookie
+resources:
+- configmap.yaml
+`,
+		"/path/to/components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie
+`,
+	}
+
+	t.Run("built-in template with Application", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		files, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "pasta", scaffold.Options{
+			Template: "deployment",
+			Values: map[string]string{
+				"Image": "quay.io/pasta/pasta:v1.0.0",
+			},
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"apps/app-pasta.yaml",
+			"apps/kustomization.yaml",
+			"components/pasta/deployment.yaml",
+			"components/pasta/kustomization.yaml",
+			"components/pasta/service.yaml",
+		}, files)
+		data, err := afs.ReadFile("/path/to/apps/app-pasta.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+spec:
+  destination:
+    namespace: pasta
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/pasta
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: main
+  syncPolicy:
+    automated:
+      prune: true
+      selfHeal: true
+    syncOptions:
+    - CreateNamespace=true
+`, string(data))
+		data, err = afs.ReadFile("/path/to/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- app-cookie.yaml
+- app-pasta.yaml
+`, string(data))
+		// the result passes the checks
+		err = validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces()).CheckApplications("apps")
+		require.NoError(t, err)
+		err = validation.CheckComponents(logger, afs, "/path/to", "components")
+		require.NoError(t, err)
+		// the result is already formatted
+		results, err := format.Files(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, false)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("built-in template with ApplicationSet", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		files, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "pasta", scaffold.Options{
+			ApplicationSet: true,
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"apps/appset-pasta.yaml",
+			"apps/kustomization.yaml",
+			"components/pasta/kustomization.yaml",
+		}, files)
+		data, err := afs.ReadFile("/path/to/apps/appset-pasta.yaml")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `      name: 'pasta-{{cluster}}'`)
+		err = validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces()).CheckApplications("apps")
+		require.NoError(t, err)
+		err = validation.CheckComponents(logger, afs, "/path/to", "components")
+		require.NoError(t, err)
+		// the result is already formatted
+		results, err := format.Files(logger, afs, "/path/to", []string{"apps"}, []string{"components"}, false)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("repo-local template", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/.templates/configmap/values.yaml": `Flavor: tomato`,
+			"/path/to/.templates/configmap/component/kustomization.yaml.tmpl": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- configmap.yaml
+`,
+			"/path/to/.templates/configmap/component/configmap.yaml.tmpl": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+data:
+  flavor: {{ .Flavor }}
+`,
+		})
+
+		// when
+		_, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "pasta", scaffold.Options{
+			Template:     "configmap",
+			TemplatesDir: scaffold.DefaultTemplatesDir,
+			Values: map[string]string{
+				"Namespace": "food",
+			},
+		})
+
+		// then
+		require.NoError(t, err)
+		data, err := afs.ReadFile("/path/to/components/pasta/configmap.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta
+data:
+  flavor: tomato
+`, string(data))
+		data, err = afs.ReadFile("/path/to/apps/app-pasta.yaml")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "    namespace: food\n")
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("missing value", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "pasta", scaffold.Options{
+				Template: "deployment",
+			})
+
+			// then
+			require.EqualError(t, err, `failed to render template component/deployment.yaml.tmpl: template: deployment.yaml.tmpl:11:18: executing "deployment.yaml.tmpl" at <.Image>: map has no entry for key "Image"`)
+			// nothing was created
+			exists, err := afs.Exists("/path/to/components/pasta")
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+
+		t.Run("existing component", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "cookie", scaffold.Options{})
+
+			// then
+			require.EqualError(t, err, "component already exists: components/cookie")
+		})
+
+		t.Run("unknown template", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "pasta", scaffold.Options{
+				Template: "unknown",
+			})
+
+			// then
+			require.EqualError(t, err, "unknown template: 'unknown' (available: deployment, kustomize)")
+		})
+
+		t.Run("invalid name", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files)
+
+			// when
+			_, err := scaffold.NewComponent(logger, afs, "/path/to", "apps", "components", "Pasta", scaffold.Options{})
+
+			// then
+			require.EqualError(t, err, "invalid component name: 'Pasta' (expected at most 63 lowercase alphanumeric characters or '-', starting and ending with an alphanumeric character)")
+		})
+	})
+}
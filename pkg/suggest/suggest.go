@@ -0,0 +1,20 @@
+package suggest
+
+import (
+	"github.com/agnivade/levenshtein"
+)
+
+// threshold is the maximum Levenshtein distance (excluded) between a name and a candidate
+// for the candidate to be suggested
+const threshold = 4
+
+// Closest returns the candidates whose Levenshtein distance with the given name is below the threshold
+func Closest(name string, candidates ...string) []string {
+	suggestions := []string{}
+	for _, c := range candidates {
+		if distance := levenshtein.ComputeDistance(name, c); distance < threshold {
+			suggestions = append(suggestions, c)
+		}
+	}
+	return suggestions
+}
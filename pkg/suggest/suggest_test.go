@@ -0,0 +1,28 @@
+package suggest_test
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/suggest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosest(t *testing.T) {
+
+	t.Run("match", func(t *testing.T) {
+		// when
+		suggestions := suggest.Closest("app-cookei", "app-cookie", "app-pasta", "appset-cookie")
+
+		// then
+		assert.Equal(t, []string{"app-cookie"}, suggestions)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		// when
+		suggestions := suggest.Closest("app-cookie", "app-pasta", "app-pizza")
+
+		// then
+		assert.Empty(t, suggestions)
+	})
+}
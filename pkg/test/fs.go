@@ -0,0 +1,26 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// NewFS returns an in-memory filesystem which contains the given files (indexed by their absolute path),
+// along with their parent directories. When a path is in more than one map, the last contents win.
+func NewFS(t *testing.T, files ...map[string]string) afero.Afero {
+	afs := afero.Afero{
+		Fs: afero.NewMemMapFs(),
+	}
+	for _, f := range files {
+		for path, data := range f {
+			err := afs.MkdirAll(filepath.Dir(path), 0755)
+			require.NoError(t, err)
+			err = afs.WriteFile(path, []byte(data), 0755)
+			require.NoError(t, err)
+		}
+	}
+	return afs
+}
@@ -0,0 +1,44 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// NewRepository initializes a Git repository in a temporary directory and returns the directory and the repository
+func NewRepository(t *testing.T) (string, *git.Repository) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	return dir, repo
+}
+
+// Commit writes the given files (indexed by their path relative to the given directory) and commits them,
+// returning the hash of the commit
+func Commit(t *testing.T, repo *git.Repository, dir string, files map[string]string) string {
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	for path, data := range files {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(dir, path), []byte(data), 0600)
+		require.NoError(t, err)
+	}
+	err = wt.AddGlob(".")
+	require.NoError(t, err)
+	h, err := wt.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "test",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+	return h.String()
+}
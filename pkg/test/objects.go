@@ -0,0 +1,25 @@
+package test
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// NewObject returns an object with the given type, namespace (if not empty), name and spec (if not nil)
+func NewObject(apiVersion, kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	if spec != nil {
+		obj.Object["spec"] = spec
+	}
+	return obj
+}
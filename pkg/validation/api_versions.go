@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/deprecations"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Verifies that the given objects (rendered from the given source) do not use an API version which is removed
+// in the target Kubernetes version, and logs a warning for each object which uses a deprecated API version.
+// All objects with a removed API version are reported, along with the replacement API version.
+func (c *Checker) checkAPIVersions(source string, objs []*unstructured.Unstructured) error {
+	if c.kubeVersion == nil {
+		return nil
+	}
+	removed := []string{}
+	for _, obj := range objs {
+		d, found := deprecations.Lookup(obj.GetAPIVersion(), obj.GetKind())
+		if !found {
+			continue
+		}
+		switch {
+		case c.kubeVersion.AtLeast(d.RemovedIn):
+			removed = append(removed, fmt.Sprintf("%s/%s uses %s which is removed in %s (%s)", obj.GetKind(), obj.GetName(), obj.GetAPIVersion(), d.RemovedIn, replacement(d)))
+		case c.kubeVersion.AtLeast(d.DeprecatedIn):
+			c.logger.Warn("⚠️ deprecated API", "source", source, "object", obj.GetKind()+"/"+obj.GetName(),
+				"apiVersion", obj.GetAPIVersion(), "removed-in", d.RemovedIn, "replacement", d.Replacement)
+		}
+	}
+	if len(removed) > 0 {
+		return fmt.Errorf("removed APIs in %s for Kubernetes %s: %s", source, c.kubeVersion, strings.Join(removed, ", "))
+	}
+	return nil
+}
+
+func replacement(d deprecations.Deprecation) string {
+	if d.Replacement == "" {
+		return "no replacement"
+	}
+	return "use " + d.Replacement
+}
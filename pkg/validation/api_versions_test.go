@@ -0,0 +1,88 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/deprecations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAPIVersions(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- resources.yaml`,
+		"/path/to/components/cookie/resources.yaml": `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cookie
+---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: cookie
+---
+apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+kind: FlowSchema
+metadata:
+  name: cookie`,
+	}
+
+	t.Run("deprecated APIs only", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithKubeVersion(deprecations.Version{Major: 1, Minor: 24})).CheckComponents("components")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("removed APIs", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files)
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithKubeVersion(deprecations.Version{Major: 1, Minor: 32})).CheckComponents("components")
+
+		// then
+		require.EqualError(t, err, "removed APIs in components/cookie for Kubernetes 1.32: "+
+			"CronJob/cookie uses batch/v1beta1 which is removed in 1.25 (use batch/v1), "+
+			"PodSecurityPolicy/cookie uses policy/v1beta1 which is removed in 1.25 (no replacement), "+
+			"FlowSchema/cookie uses flowcontrol.apiserver.k8s.io/v1beta3 which is removed in 1.32 (use flowcontrol.apiserver.k8s.io/v1)")
+	})
+
+	t.Run("removed API in Application", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithKubeVersion(deprecations.Version{Major: 1, Minor: 29})).CheckApplications("apps")
+
+		// then
+		require.ErrorContains(t, err, "removed APIs in application 'app-cookie' in apps/app.yaml for Kubernetes 1.29: CronJob/cookie uses batch/v1beta1")
+	})
+}
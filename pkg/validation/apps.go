@@ -4,22 +4,33 @@ import (
 	"fmt"
 	iofs "io/fs"
 	"path/filepath"
+	"strings"
 
-	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
 
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/afero"
-	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
 )
 
-// Look for all YAML files in the given paths and when the contents if an Argo CD Application or ApplicationSet,
-// verify that the `spec.source.path` matches an existing component
+// Checks the Applications and ApplicationSets in the given paths with a default Checker (see `Checker.CheckApplications`)
 func CheckApplications(logger *log.Logger, afs afero.Afero, baseDir string, apps ...string) error {
+	return NewChecker(logger, afs, baseDir).CheckApplications(apps...)
+}
 
+// Look for all YAML files in the given paths and when the contents if an Argo CD Application or ApplicationSet,
+// verify that the path of each source (`spec.source.path` or `spec.sources[*].path`) matches an existing component
+func (c *Checker) CheckApplications(apps ...string) error {
+	logger, afs, baseDir := c.logger, c.afs, c.baseDir
 	for _, path := range apps {
 		p := filepath.Join(baseDir, path)
 		logger.Info("👀 checking Applications and ApplicationSets", "path", path)
-		fsys, err := NewInMemoryFS(logger, afs, p)
+		fsys, err := c.inMemoryFS(p)
 		if err != nil {
 			return err
 		}
@@ -29,13 +40,19 @@ func CheckApplications(logger *log.Logger, afs afero.Afero, baseDir string, apps
 				return err
 			}
 			if info.IsDir() {
+				if !c.selected(path) {
+					return nil
+				}
 				logger.Debug("👀 checking contents", "path", path)
-				if kpath, found := lookupKustomizationFile(logger, afs, path); found {
-					if err := checkKustomizeResources(logger, afs, baseDir, kpath); err != nil {
+				if err := c.checkSecretFiles(fsys, path); err != nil {
+					return err
+				}
+				if kpath, found := kustomizations.Lookup(logger, afs, path); found {
+					if err := c.checkKustomizeResources(kpath); err != nil {
 						return err
 					}
 					if info.Name() != "base" {
-						if err := checkBuild(logger, fsys, path); err != nil {
+						if err := c.checkBuild(fsys, path); err != nil {
 							return err
 						}
 					}
@@ -49,12 +66,30 @@ func CheckApplications(logger *log.Logger, afs afero.Afero, baseDir string, apps
 			if filepath.Ext(info.Name()) == ".yaml" {
 				logger.Debug("checking contents", "path", path)
 				app := &argocdv1alpha1.Application{}
-				if err := yaml.Unmarshal(data, app); err == nil && app.Spec.Source != nil {
-					return checkPath(afs, baseDir, app.Spec.Source.Path)
+				if err := yaml.Unmarshal(data, app); err == nil && (app.Spec.HasMultipleSources() || app.Spec.Source != nil) {
+					if !c.selected(path) && !c.selectedSource(app.Spec) {
+						return nil
+					}
+					if err := c.checkSourcePaths(path, app.Spec); err != nil {
+						return err
+					}
+					if err := c.checkSyncPolicy(path, app.Name, app.Spec.SyncPolicy); err != nil {
+						return err
+					}
+					return c.checkApplication(path, app)
 				}
 				appSet := &argocdv1alpha1.ApplicationSet{}
-				if err := yaml.Unmarshal(data, appSet); err == nil && appSet.Spec.Template.Spec.Source != nil {
-					return checkPath(afs, baseDir, appSet.Spec.Template.Spec.Source.Path)
+				if err := yaml.Unmarshal(data, appSet); err == nil && (appSet.Spec.Template.Spec.HasMultipleSources() || appSet.Spec.Template.Spec.Source != nil) {
+					if !c.selected(path) && !c.selectedSource(appSet.Spec.Template.Spec) {
+						return nil
+					}
+					if err := c.checkSourcePaths(path, appSet.Spec.Template.Spec); err != nil {
+						return err
+					}
+					if err := c.checkSyncPolicy(path, appSet.Name, appSet.Spec.Template.Spec.SyncPolicy); err != nil {
+						return err
+					}
+					return c.checkApplicationSet(path, appSet)
 				}
 			}
 			return nil
@@ -65,6 +100,34 @@ func CheckApplications(logger *log.Logger, afs afero.Afero, baseDir string, apps
 	return nil
 }
 
+// returns true if the path of one of the sources of the given Application (or ApplicationSet template) is selected
+func (c *Checker) selectedSource(spec argocdv1alpha1.ApplicationSpec) bool {
+	for _, source := range spec.GetSources() {
+		if source.Path != "" && c.selected(filepath.Join(c.baseDir, source.Path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifies that the path of each source of the given Application (or ApplicationSet template) matches an existing component
+// and that its Kustomize options are valid.
+// The sources of a multi-source Application without a path (eg: Helm charts or `ref` sources) are not checked.
+func (c *Checker) checkSourcePaths(path string, spec argocdv1alpha1.ApplicationSpec) error {
+	for _, source := range spec.GetSources() {
+		if spec.HasMultipleSources() && source.Path == "" {
+			continue
+		}
+		if err := checkPath(c.afs, c.baseDir, source.Path); err != nil {
+			return err
+		}
+		if err := c.checkKustomizeOptions(path, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func checkPath(afs afero.Afero, repoURL, path string) error {
 	p := filepath.Join(repoURL, path)
 	if _, err := afs.ReadDir(p); err != nil {
@@ -77,3 +140,150 @@ func checkPath(afs afero.Afero, repoURL, path string) error {
 
 	return nil
 }
+
+// Verifies that `kustomize build` completes successfully on the source path once the Application-level Kustomize options
+// (images, namePrefix, nameSuffix, commonLabels, namespace, patches, components, etc.) have been applied
+// as Argo CD would do.
+// Options of an ApplicationSet template which contain a `{{...}}` placeholder are not checked.
+func (c *Checker) checkKustomizeOptions(path string, source argocdv1alpha1.ApplicationSource) error {
+	if source.Kustomize == nil {
+		return nil
+	}
+	if data, err := yaml.Marshal(source.Kustomize); err != nil {
+		return err
+	} else if strings.Contains(string(data), "{{") {
+		c.logger.Debug("skipping templated Kustomize options", "path", path)
+		return nil
+	}
+	c.logger.Debug("👀 checking kustomize build with Application options", "path", path)
+	// use the whole repository, since the options may refer to files outside of the source path
+	fsys, err := c.inMemoryFS(c.baseDir)
+	if err != nil {
+		return err
+	}
+	if _, err := render.KustomizeWithOptions(c.logger, fsys, filepath.Join(c.baseDir, source.Path), source.Kustomize); err != nil {
+		rpath, _ := filepath.Rel(c.baseDir, path)
+		return fmt.Errorf("invalid Kustomize options in %s: %w", rpath, err)
+	}
+	return nil
+}
+
+// Runs the checks on the Applications generated by the given ApplicationSet (see `applications.GenerateApplications`)
+func (c *Checker) checkApplicationSet(path string, appSet *argocdv1alpha1.ApplicationSet) error {
+	if c.clusters == nil && !c.rendering() {
+		return nil
+	}
+	generated, err := applications.GenerateApplications(appSet)
+	if err != nil {
+		return err
+	}
+	for _, app := range generated {
+		if err := c.checkApplication(path, app); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs the checks on the destination and on the rendered manifests of the given Application
+// (or Application generated by an ApplicationSet).
+// Sources whose path contains a `{{...}}` placeholder are not rendered.
+func (c *Checker) checkApplication(path string, app *argocdv1alpha1.Application) error {
+	if c.clusters != nil {
+		if err := c.checkDestination(path, app); err != nil {
+			return err
+		}
+	}
+	checkProject := c.checksProject(path, app)
+	if !checkProject && !c.renderingWithoutProjects() {
+		return nil
+	}
+	var objs []*unstructured.Unstructured
+	if templated(app) {
+		c.logger.Debug("skipping rendering of templated Application", "path", path, "name", app.Name)
+	} else {
+		c.logger.Debug("👀 rendering Application", "path", path, "name", app.Name)
+		fsys, err := c.inMemoryFS(c.baseDir)
+		if err != nil {
+			return err
+		}
+		if objs, err = c.render(fsys, app); err != nil {
+			rpath, _ := filepath.Rel(c.baseDir, path)
+			return fmt.Errorf("failed to render Application '%s' in %s: %w", app.Name, rpath, err)
+		}
+	}
+	if c.namespaces {
+		if err := c.checkNamespaces(path, app, objs); err != nil {
+			return err
+		}
+	}
+	if c.kubeVersion != nil {
+		rpath, _ := filepath.Rel(c.baseDir, path)
+		if err := c.checkAPIVersions(fmt.Sprintf("application '%s' in %s", app.Name, rpath), objs); err != nil {
+			return err
+		}
+	}
+	if c.imagePolicy != nil || c.imageInventory != nil {
+		if err := c.checkImages(path, app, objs); err != nil {
+			return err
+		}
+	}
+	if checkProject {
+		return c.checkProject(path, app, objs)
+	}
+	return nil
+}
+
+// renders the sources of the given Application, except the Helm charts of remote repositories which can't be rendered offline
+func (c *Checker) render(fsys kfsys.FileSystem, app *argocdv1alpha1.Application) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+	for _, source := range app.Spec.GetSources() {
+		switch {
+		case source.Path == "" && source.Ref != "":
+			// source only used as a reference to other files (eg: Helm value files)
+			continue
+		case source.Chart != "":
+			c.logger.Debug("skipping rendering of remote Helm chart", "name", app.Name, "chart", source.Chart)
+			continue
+		}
+		o, err := render.Source(c.logger, fsys, c.baseDir, app, source)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, o...)
+	}
+	return objs, nil
+}
+
+// returns true if a check needs the rendered manifests of the Applications
+func (c *Checker) rendering() bool {
+	return c.projects != nil || c.renderingWithoutProjects()
+}
+
+// returns true if a check other than the verification against the AppProjects needs the rendered manifests of the Applications
+func (c *Checker) renderingWithoutProjects() bool {
+	return c.namespaces || c.imagePolicy != nil || c.imageInventory != nil || c.kubeVersion != nil
+}
+
+// returns true if the given Application must be checked against its AppProject, ie: the projects are known
+// and the Application's project is one of them (unless the unknown projects are reported)
+func (c *Checker) checksProject(path string, app *argocdv1alpha1.Application) bool {
+	if c.projects == nil {
+		return false
+	}
+	if _, found := c.project(app); found || !c.skipUnknownProjects {
+		return true
+	}
+	c.logger.Debug("skipping verification of Application whose project is not in the repository", "path", path, "name", app.Name, "project", app.Spec.Project)
+	return false
+}
+
+// returns true if the path of one of the Application sources contains a `{{...}}` placeholder
+func templated(app *argocdv1alpha1.Application) bool {
+	for _, source := range app.Spec.GetSources() {
+		if applications.IsTemplated(source.Path) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
 	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
 
 	"github.com/charmbracelet/log"
@@ -355,6 +356,39 @@ spec:
 			// then
 			require.EqualError(t, err, "components/cookie is not valid")
 		})
+
+		t.Run("unknown path in multiple sources", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, map[string]string{
+				"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml`,
+				"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  sources:
+  - repoURL: https://charts.example.com
+    chart: cookie
+    targetRevision: 1.0.0
+  - path: components/cookie
+  - path: components/pasta # path does not exist`,
+				"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1`,
+			})
+
+			// when
+			err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+			// then
+			require.EqualError(t, err, "components/pasta is not valid")
+		})
 		t.Run("missing component kustomization.yaml", func(t *testing.T) {
 
 			// given
@@ -475,6 +509,162 @@ spec:
 			require.EqualError(t, err, "components/cookie does not contain a 'kustomization.yaml' file")
 		})
 	})
+
+	t.Run("kustomization with app kustomize options", func(t *testing.T) {
+
+		// given
+		files := map[string]string{
+			"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml`,
+			"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- deployment.yaml`,
+			"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  replicas: 1`,
+			"/path/to/components/cookie/_patches/replicas.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  replicas: 2`,
+			"/path/to/components/shared/kustomization.yaml": `kind: Component
+apiVersion: kustomize.config.k8s.io/v1alpha1
+commonAnnotations:
+  shared: "true"`,
+		}
+
+		t.Run("valid options", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files, map[string]string{
+				"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      namePrefix: yummy-
+      namespace: cookie
+      images:
+      - nginx:1.27
+      commonLabels:
+        app: cookie
+      patches:
+      - path: _patches/replicas.yaml
+      - patch: |-
+          - op: replace
+            path: /spec/replicas
+            value: 3
+        target:
+          kind: Deployment
+          name: cookie
+      components:
+      - ../shared`,
+			})
+
+			// when
+			err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+			// then
+			require.NoError(t, err)
+		})
+
+		t.Run("missing patch file", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files, map[string]string{
+				"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      patches:
+      - path: _patches/replica.yaml`,
+			})
+
+			// when
+			err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+			// then
+			require.EqualError(t, err, "invalid Kustomize options in apps/app-cookie.yaml: patch does not exist: _patches/replica.yaml")
+		})
+
+		t.Run("broken inline patch", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files, map[string]string{
+				"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      patches:
+      - patch: |-
+          - op: replace
+            path: /spec/template/spec/replicas
+            value: 3
+        target:
+          kind: Deployment
+          name: cookie`,
+			})
+
+			// when
+			err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+			// then
+			require.ErrorContains(t, err, "invalid Kustomize options in apps/app-cookie.yaml: ")
+		})
+
+		t.Run("missing component", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := test.NewFS(t, files, map[string]string{
+				"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      components:
+      - ../pasta`,
+			})
+
+			// when
+			err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+			// then
+			require.EqualError(t, err, "invalid Kustomize options in apps/app-cookie.yaml: component does not exist: ../pasta")
+		})
+	})
 }
 
 func addFile(afs afero.Afero, path string, data string) error {
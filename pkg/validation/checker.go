@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/deprecations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Checker verifies the Argo CD configuration (Applications, ApplicationSets and components)
+// of the repository in the base directory
+type Checker struct {
+	logger  *log.Logger
+	afs     afero.Afero
+	baseDir string
+	// when not nil, only the kustomizations in the selected directories and the Applications
+	// in the selected files (or whose source path is a selected directory) are checked
+	selection map[string]bool
+	// when not nil, the Applications (and Applications generated by the ApplicationSets) are checked
+	// against the constraints of their AppProject, by name
+	projects map[string]*argocdv1alpha1.AppProject
+	// when true, the Applications whose AppProject is unknown are not checked against it (instead of failing)
+	skipUnknownProjects bool
+	// when not nil, the destinations of the Applications (and Applications generated by the ApplicationSets)
+	// must resolve to a cluster of the inventory
+	clusters clusters.Inventory
+	// when true, the namespaces of the rendered objects are checked against the destination
+	// of the Applications (and Applications generated by the ApplicationSets)
+	namespaces bool
+	// when true, the raw files and the rendered objects are checked for sensitive data (Secrets and high-entropy values),
+	// except for the paths matching one of the allowed patterns
+	secrets        bool
+	allowedSecrets []string
+	// when not nil, the images of the workloads rendered by the Applications must follow the policy
+	imagePolicy *images.Policy
+	// when not nil, the images of the workloads rendered by the Applications are recorded in the inventory
+	imageInventory *[]images.Inventory
+	// when not nil, the rendered objects must not use an API version which is removed in this Kubernetes version
+	kubeVersion *deprecations.Version
+	// when not nil, the unreferenced resources and the references to missing resources are fixed
+	// in the Kustomization files, and the edits are recorded
+	fixes *[]Fix
+	// expected repository URL and target revision of the Applications and ApplicationSets, by directory
+	expectations []SourceExpectation
+	// in-memory filesystems used to run `kustomize build`, by root path
+	fsys map[string]kfsys.FileSystem
+}
+
+// Option to configure the Checker
+type Option func(*Checker)
+
+// WithSelection restricts the checks to the kustomizations in the given directories
+// and to the Applications and ApplicationSets in the given files or whose source path is one of the given directories
+func WithSelection(paths ...string) Option {
+	return func(c *Checker) {
+		c.selection = make(map[string]bool, len(paths))
+		for _, p := range paths {
+			c.selection[filepath.Clean(p)] = true
+		}
+	}
+}
+
+// WithProjects enables the verification of the Applications and ApplicationSets against the constraints
+// (source repositories, destinations and resource kinds) of the given AppProjects
+func WithProjects(projects ...*argocdv1alpha1.AppProject) Option {
+	return func(c *Checker) {
+		c.projects = make(map[string]*argocdv1alpha1.AppProject, len(projects))
+		for _, p := range projects {
+			c.projects[p.Name] = p
+		}
+	}
+}
+
+// WithRepositoryProjects enables the verification of the Applications and ApplicationSets against the constraints
+// of the given AppProjects, found in the repository. Contrary to `WithProjects`, the Applications whose project is not
+// one of them (eg: a project which only exists in the cluster) are not checked against their project.
+func WithRepositoryProjects(projects ...*argocdv1alpha1.AppProject) Option {
+	return func(c *Checker) {
+		WithProjects(projects...)(c)
+		c.skipUnknownProjects = true
+	}
+}
+
+// WithClusters enables the verification of the Application and ApplicationSet destinations
+// against the given cluster inventory
+func WithClusters(inventory clusters.Inventory) Option {
+	return func(c *Checker) {
+		c.clusters = inventory
+	}
+}
+
+// WithSourceExpectations configures the expected repository URL and target revision
+// of the Applications and ApplicationSets in each directory (see `Checker.CheckSources`)
+func WithSourceExpectations(expectations ...SourceExpectation) Option {
+	return func(c *Checker) {
+		c.expectations = expectations
+	}
+}
+
+// WithNamespaces enables the verification of the namespaces of the rendered objects
+// against the destination namespace of the Applications (see `Checker.checkNamespaces`)
+func WithNamespaces() Option {
+	return func(c *Checker) {
+		c.namespaces = true
+	}
+}
+
+// WithSecrets enables the verification that the raw files and the rendered objects do not contain sensitive data,
+// except for the files and kustomizations whose path (relative to the base directory) or one of its parent directories
+// matches one of the given patterns (see `filepath.Match`)
+func WithSecrets(allowed ...string) Option {
+	return func(c *Checker) {
+		c.secrets = true
+		c.allowedSecrets = cleanPatterns(allowed)
+	}
+}
+
+// WithImagePolicy enables the verification of the images of the workloads rendered by the Applications
+// and of the Kustomize image overrides of the Applications against the given policy
+func WithImagePolicy(policy images.Policy) Option {
+	return func(c *Checker) {
+		c.imagePolicy = &policy
+	}
+}
+
+// WithImageInventory records the images of the workloads rendered by each Application in the given inventory
+func WithImageInventory(inventory *[]images.Inventory) Option {
+	return func(c *Checker) {
+		c.imageInventory = inventory
+	}
+}
+
+// WithKubeVersion enables the verification that the objects rendered by the components and the Applications
+// do not use an API version which is removed in the given Kubernetes version
+func WithKubeVersion(version deprecations.Version) Option {
+	return func(c *Checker) {
+		c.kubeVersion = &version
+	}
+}
+
+// WithFix enables the fix mode, in which the local files and directories which are not referenced in the `resources`
+// of a Kustomization file are added to it, and the `resources` entries which refer to missing local files or directories
+// are removed from it. The edits are recorded in the given list.
+func WithFix(fixes *[]Fix) Option {
+	return func(c *Checker) {
+		c.fixes = fixes
+	}
+}
+
+func NewChecker(logger *log.Logger, afs afero.Afero, baseDir string, options ...Option) *Checker {
+	c := &Checker{
+		logger:  logger,
+		afs:     afs,
+		baseDir: baseDir,
+		fsys:    map[string]kfsys.FileSystem{},
+	}
+	for _, apply := range options {
+		apply(c)
+	}
+	return c
+}
+
+// returns true if the given path should be checked
+func (c *Checker) selected(path string) bool {
+	return c.selection == nil || c.selection[filepath.Clean(path)]
+}
+
+// returns the in-memory filesystem with the contents of the given root path,
+// which is created on the first call and reused afterwards
+func (c *Checker) inMemoryFS(root string) (kfsys.FileSystem, error) {
+	if fsys, found := c.fsys[root]; found {
+		return fsys, nil
+	}
+	fsys, err := NewInMemoryFS(c.logger, c.afs, root)
+	if err != nil {
+		return nil, err
+	}
+	c.fsys[root] = fsys
+	return fsys, nil
+}
@@ -4,18 +4,25 @@ import (
 	"io/fs"
 	"path/filepath"
 
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
 	"github.com/charmbracelet/log"
 	"github.com/spf13/afero"
 )
 
-// Looks for a `kustomization.yaml` file in all `components` directories and subdirs,
-// and attempt to run `kustomize build`
+// Checks the components in the given paths with a default Checker (see `Checker.CheckComponents`)
 func CheckComponents(logger *log.Logger, afs afero.Afero, baseDir string, components ...string) error {
+	return NewChecker(logger, afs, baseDir).CheckComponents(components...)
+}
 
+// Looks for a `kustomization.yaml` file in all `components` directories and subdirs,
+// and attempt to run `kustomize build`
+func (c *Checker) CheckComponents(components ...string) error {
+	logger, afs, baseDir := c.logger, c.afs, c.baseDir
 	for _, path := range components {
 		p := filepath.Join(baseDir, path)
 		logger.Info("👀 checking components", "path", path)
-		fsys, err := NewInMemoryFS(logger, afs, p)
+		fsys, err := c.inMemoryFS(p)
 		if err != nil {
 			return err
 		}
@@ -24,18 +31,21 @@ func CheckComponents(logger *log.Logger, afs afero.Afero, baseDir string, compon
 				logger.Error("prevent panic by handling failure", "path", path)
 				return err
 			}
-			if !d.IsDir() {
+			if !d.IsDir() || !c.selected(path) {
 				// skip
 				return nil
 			}
+			if err := c.checkSecretFiles(fsys, path); err != nil {
+				return err
+			}
 			// look for a Kustomization file in the directory
-			if kp, found := lookupKustomizationFile(logger, afs, path); found {
-				if err := checkKustomizeResources(logger, afs, baseDir, kp); err != nil {
+			if kp, found := kustomizations.Lookup(logger, afs, path); found {
+				if err := c.checkKustomizeResources(kp); err != nil {
 					return err
 				}
 				if d.Name() != "base" {
 					logger.Debug("checking Kustomization build ", "path", path)
-					if err := checkBuild(logger, fsys, path); err != nil {
+					if err := c.checkBuild(fsys, path); err != nil {
 						return err
 					}
 				}
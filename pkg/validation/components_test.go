@@ -302,5 +302,163 @@ data:
 			// then
 			require.EqualError(t, err, "resource is not referenced in components/kustomization.yaml: configmap2.yaml")
 		})
+
+		t.Run("component with missing resource", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := afero.Afero{
+				Fs: afero.NewMemMapFs(),
+			}
+			err := afs.Mkdir("/path/to/components", os.ModeDir)
+			require.NoError(t, err)
+			err = addFile(afs, "/path/to/components/kustomization.yaml", `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+
+resources:
+  - configmap1.yaml
+  - configmap2.yaml`)
+			require.NoError(t, err)
+			err = addFile(afs, "/path/to/components/configmap1.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  namespace: test
+  name: config1
+data:
+  cookie: yummy`)
+			require.NoError(t, err)
+
+			// when
+			err = validation.CheckComponents(logger, afs, "/path/to", "components")
+
+			// then
+			require.EqualError(t, err, "resource referenced in components/kustomization.yaml does not exist: configmap2.yaml (did you mean: configmap1.yaml?)")
+		})
+
+		t.Run("base component with missing patch", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := afero.Afero{
+				Fs: afero.NewMemMapFs(),
+			}
+			err := afs.MkdirAll("/path/to/components/cookie/base", os.ModeDir)
+			require.NoError(t, err)
+			err = addFile(afs, "/path/to/components/cookie/base/kustomization.yaml", `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+
+resources:
+  - https://github.com/codeready-toolchain/sandbox-argocd/components/pasta?ref=master
+  - deployment.yaml
+
+patches:
+  - path: pacth.yaml`)
+			require.NoError(t, err)
+			err = addFile(afs, "/path/to/components/cookie/base/deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  namespace: test
+  name: test
+spec:
+  replicas: 1
+`)
+			require.NoError(t, err)
+
+			// when
+			err = validation.CheckComponents(logger, afs, "/path/to", "components")
+
+			// then
+			require.EqualError(t, err, "resource referenced in components/cookie/base/kustomization.yaml does not exist: pacth.yaml")
+		})
+
+		t.Run("component with missing base", func(t *testing.T) {
+			// given
+			logger := log.New(os.Stdout)
+			afs := afero.Afero{
+				Fs: afero.NewMemMapFs(),
+			}
+			err := afs.MkdirAll("/path/to/components/cookie/base", os.ModeDir)
+			require.NoError(t, err)
+			err = addFile(afs, "/path/to/components/cookie/base/kustomization.yaml", `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1`)
+			require.NoError(t, err)
+			err = addFile(afs, "/path/to/components/cookie/dev/kustomization.yaml", `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+
+resources:
+  - ../bsae`)
+			require.NoError(t, err)
+
+			// when
+			err = validation.CheckComponents(logger, afs, "/path/to", "components")
+
+			// then
+			require.EqualError(t, err, "resource referenced in components/cookie/dev/kustomization.yaml does not exist: ../bsae (did you mean: ../base?)")
+		})
+	})
+}
+
+func TestCheckComponentsWithSelection(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	afs := afero.Afero{
+		Fs: afero.NewMemMapFs(),
+	}
+	// `cookie` component is valid
+	err := addFile(afs, "/path/to/components/cookie/kustomization.yaml", `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+
+resources:
+  - configmap.yaml`)
+	require.NoError(t, err)
+	err = addFile(afs, "/path/to/components/cookie/configmap.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  namespace: test
+  name: cookie
+data:
+  cookie: yummy`)
+	require.NoError(t, err)
+	// `pasta` component has an unreferenced resource
+	err = addFile(afs, "/path/to/components/pasta/kustomization.yaml", `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+
+resources:
+  - configmap.yaml`)
+	require.NoError(t, err)
+	err = addFile(afs, "/path/to/components/pasta/configmap.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  namespace: test
+  name: pasta
+data:
+  pasta: yummy`)
+	require.NoError(t, err)
+	err = addFile(afs, "/path/to/components/pasta/secret.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  namespace: test
+  name: pasta`)
+	require.NoError(t, err)
+
+	t.Run("unselected invalid component", func(t *testing.T) {
+		// given
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithSelection("/path/to/components/cookie"))
+
+		// when
+		err := checker.CheckComponents("components")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("selected invalid component", func(t *testing.T) {
+		// given
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithSelection("/path/to/components/pasta"))
+
+		// when
+		err := checker.CheckComponents("components")
+
+		// then
+		require.EqualError(t, err, "resource is not referenced in components/pasta/kustomization.yaml: secret.yaml")
 	})
 }
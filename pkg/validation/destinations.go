@@ -0,0 +1,40 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/suggest"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// Verifies that the destination of the given Application (or Application generated by an ApplicationSet)
+// resolves to a cluster of the inventory, either by server URL or by name.
+// Destinations which contain a `{{...}}` placeholder are not checked.
+func (c *Checker) checkDestination(path string, app *argocdv1alpha1.Application) error {
+	dest := app.Spec.Destination
+	if applications.IsTemplated(dest.Server) || applications.IsTemplated(dest.Name) {
+		c.logger.Debug("skipping templated destination", "path", path, "name", app.Name)
+		return nil
+	}
+	if _, found := c.clusters.Lookup(dest.Server, dest.Name); found {
+		return nil
+	}
+	rpath, _ := filepath.Rel(c.baseDir, path)
+	if dest.Server == "" && dest.Name == "" {
+		return fmt.Errorf("application '%s' in %s has no destination server or name", app.Name, rpath)
+	}
+	kind, value, candidates := "server", dest.Server, c.clusters.Servers()
+	if dest.Server == "" {
+		kind, value, candidates = "name", dest.Name, c.clusters.Names()
+	}
+	msg := fmt.Sprintf("application '%s' in %s has an unknown destination %s: '%s'", app.Name, rpath, kind, value)
+	if suggestions := suggest.Closest(value, candidates...); len(suggestions) > 0 {
+		return fmt.Errorf("%s (did you mean: %s?)", msg, strings.Join(suggestions, ", "))
+	}
+	return errors.New(msg)
+}
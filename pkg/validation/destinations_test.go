@@ -0,0 +1,190 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/clusters"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckApplicationsWithClusters(t *testing.T) {
+
+	// given
+	inventory := clusters.Inventory{
+		clusters.InCluster,
+		{
+			Name:   "member-1",
+			Server: "https://api.member-1:6443",
+		},
+		{
+			Name:   "member-2",
+			Server: "https://api.member-2:6443",
+		},
+	}
+	files := map[string]string{
+		"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app.yaml`,
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1`,
+	}
+
+	t.Run("known server", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://api.member-1:6443
+  project: default
+  source:
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithClusters(inventory)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("known name", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    name: member-2
+  project: default
+  source:
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithClusters(inventory)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown server", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://api.membr-1:6443
+  project: default
+  source:
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithClusters(inventory)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has an unknown destination server: 'https://api.membr-1:6443' (did you mean: https://api.member-1:6443, https://api.member-2:6443?)")
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    name: host
+  project: default
+  source:
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithClusters(inventory)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has an unknown destination name: 'host'")
+	})
+
+	t.Run("appset with list generator", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-cookie
+spec:
+  generators:
+  - list:
+      elements:
+      - cluster: member-1
+      - cluster: membre-3
+  template:
+    metadata:
+      name: 'cookie-{{cluster}}'
+    spec:
+      destination:
+        name: '{{cluster}}'
+      project: default
+      source:
+        path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithClusters(inventory)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'cookie-membre-3' in apps/app.yaml has an unknown destination name: 'membre-3' (did you mean: member-1, member-2?)")
+	})
+
+	t.Run("appset with cluster generator", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-cookie
+spec:
+  generators:
+  - clusters: {}
+  template:
+    metadata:
+      name: 'cookie-{{name}}'
+    spec:
+      destination:
+        server: '{{server}}'
+      project: default
+      source:
+        path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithClusters(inventory)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+}
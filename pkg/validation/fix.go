@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+)
+
+// Fix the entries added to and removed from the `resources` of a Kustomization file in fix mode
+type Fix struct {
+	// Path of the Kustomization file (relative to the base directory)
+	Path    string
+	Added   []string
+	Removed []string
+}
+
+// Verifies the resources of the Kustomization file (see `checkKustomizeResources`), after fixing them in fix mode
+func (c *Checker) checkKustomizeResources(kpath string) error {
+	if c.fixes != nil {
+		if err := c.fixKustomizeResources(kpath); err != nil {
+			return err
+		}
+	}
+	return checkKustomizeResources(c.logger, c.afs, c.baseDir, kpath)
+}
+
+// Adds the unreferenced local files and directories to the `resources` of the Kustomization file,
+// and removes the entries of `resources` which refer to local files or directories which do not exist.
+// The in-memory filesystems used to run `kustomize build` are updated accordingly.
+// The Kustomization file is restored when the fix makes `kustomize build` fail (eg: when an unreferenced YAML file
+// is not a Kubernetes manifest), so that the problem is reported by the check instead. As in the checks, the build of
+// the `base` directories is not verified.
+func (c *Checker) fixKustomizeResources(kpath string) error {
+	kobj, err := kustomizations.Read(c.afs, kpath)
+	if err != nil {
+		return err
+	}
+	added, err := unreferencedResources(c.logger, c.afs, kpath, kobj)
+	if err != nil {
+		return err
+	}
+	removed := []string{}
+	for _, r := range kobj.Resources {
+		if kustomizations.IsInline(r) || kustomizations.IsRemote(r) {
+			continue
+		}
+		if exists, err := c.afs.Exists(filepath.Join(filepath.Dir(kpath), r)); err != nil {
+			return err
+		} else if !exists {
+			removed = append(removed, r)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	info, err := c.afs.Stat(kpath)
+	if err != nil {
+		return err
+	}
+	original, err := c.afs.ReadFile(kpath)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(kpath)
+	fsys, err := c.inMemoryFS(c.baseDir)
+	if err != nil {
+		return err
+	}
+	// only a build which the fix breaks leads to a revert
+	verify := filepath.Base(dir) != "base"
+	if verify {
+		if _, err := render.Kustomize(fsys, dir); err != nil {
+			c.logger.Debug("kustomization build already fails before the fix", "path", dir, "err", err)
+			verify = false
+		}
+	}
+	if _, err := kustomizations.RemoveResources(c.afs, kpath, removed...); err != nil {
+		return err
+	}
+	if _, err := kustomizations.AddResources(c.afs, kpath, added...); err != nil {
+		return err
+	}
+	if err := c.updateInMemoryFS(kpath); err != nil {
+		return err
+	}
+	rkpath, _ := filepath.Rel(c.baseDir, kpath)
+	if verify {
+		if _, err := render.Kustomize(fsys, dir); err != nil {
+			c.logger.Warn("🔧 reverting the fix of the kustomization resources since the build fails", "path", rkpath, "err", err)
+			if err := c.afs.WriteFile(kpath, original, info.Mode()); err != nil {
+				return err
+			}
+			return c.updateInMemoryFS(kpath)
+		}
+	}
+	c.logger.Debug("🔧 fixed kustomization resources", "path", rkpath, "added", len(added), "removed", len(removed))
+	*c.fixes = append(*c.fixes, Fix{
+		Path:    rkpath,
+		Added:   added,
+		Removed: removed,
+	})
+	return nil
+}
+
+// updates the given path in the in-memory filesystems which contain it
+func (c *Checker) updateInMemoryFS(path string) error {
+	for root, fsys := range c.fsys {
+		if kustomizations.Contains(root, path) {
+			if err := UpdateInMemoryFS(c.logger, c.afs, fsys, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFix(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	afs := afero.Afero{
+		Fs: afero.NewMemMapFs(),
+	}
+	for path, data := range map[string]string{
+		"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+# the Applications
+- app-cookie.yaml
+- app-deleted.yaml # no longer exists
+`,
+		"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/cookie`,
+		"/path/to/apps/app-pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  source:
+    path: components/cookie`,
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+namespace: cookie # the namespace
+resources:
+- configmap.yaml
+`,
+		"/path/to/components/cookie/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cookie`,
+		"/path/to/components/cookie/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: cookie`,
+		"/path/to/components/cookie/_ignored.yaml": ``,
+	} {
+		err := addFile(afs, path, data)
+		require.NoError(t, err)
+	}
+	fixes := []validation.Fix{}
+	checker := validation.NewChecker(logger, afs, "/path/to", validation.WithFix(&fixes), validation.WithNamespaces())
+
+	// when
+	err := checker.CheckApplications("apps")
+	require.NoError(t, err)
+	err = checker.CheckComponents("components")
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, []validation.Fix{
+		{
+			Path:    "apps/kustomization.yaml",
+			Added:   []string{"app-pasta.yaml"},
+			Removed: []string{"app-deleted.yaml"},
+		},
+		{
+			Path:    "components/cookie/kustomization.yaml",
+			Added:   []string{"service.yaml"},
+			Removed: []string{},
+		},
+	}, fixes)
+	data, err := afs.ReadFile("/path/to/apps/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+# the Applications
+- app-cookie.yaml
+- app-pasta.yaml
+`, string(data))
+	data, err = afs.ReadFile("/path/to/components/cookie/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+namespace: cookie # the namespace
+resources:
+- configmap.yaml
+- service.yaml
+`, string(data))
+
+	t.Run("nothing to fix", func(t *testing.T) {
+		// given
+		fixes := []validation.Fix{}
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithFix(&fixes))
+
+		// when
+		err := checker.CheckApplications("apps")
+		require.NoError(t, err)
+		err = checker.CheckComponents("components")
+		require.NoError(t, err)
+
+		// then
+		assert.Empty(t, fixes)
+	})
+	t.Run("files referenced outside of resources", func(t *testing.T) {
+		// given
+		fixes := []validation.Fix{}
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/components/pasta/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- configmap.yaml
+components:
+- variants/dev
+crds:
+- crd.yaml
+configurations:
+- configuration.yaml
+replacements:
+- path: replacement.yaml
+openapi:
+  path: schema.yaml`,
+			"/path/to/components/pasta/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta
+data:
+  env: prod`,
+			"/path/to/components/pasta/variants/dev/kustomization.yaml": `kind: Component
+apiVersion: kustomize.config.k8s.io/v1alpha1
+patches:
+- target:
+    kind: ConfigMap
+  patch: |-
+    - op: replace
+      path: /data/env
+      value: dev`,
+			"/path/to/components/pasta/crd.yaml":           `{}`,
+			"/path/to/components/pasta/configuration.yaml": `nameReference: []`,
+			"/path/to/components/pasta/replacement.yaml": `source:
+  kind: ConfigMap
+  fieldPath: data.env
+targets:
+- select:
+    kind: ConfigMap
+  fieldPaths:
+  - metadata.labels.env
+  options:
+    create: true`,
+			"/path/to/components/pasta/schema.yaml": `definitions: {}`,
+		})
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithFix(&fixes))
+
+		// when
+		err := checker.CheckComponents("components")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, fixes)
+	})
+
+	t.Run("fix reverted when the build fails", func(t *testing.T) {
+		// given
+		fixes := []validation.Fix{}
+		kustomization := `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- configmap.yaml
+`
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/components/pasta/kustomization.yaml": kustomization,
+			"/path/to/components/pasta/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta`,
+			// not a Kubernetes manifest
+			"/path/to/components/pasta/values.yaml": `replicas: 1`,
+		})
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithFix(&fixes))
+
+		// when
+		err := checker.CheckComponents("components")
+
+		// then
+		require.EqualError(t, err, "resource is not referenced in components/pasta/kustomization.yaml: values.yaml")
+		assert.Empty(t, fixes)
+		data, err := afs.ReadFile("/path/to/components/pasta/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, kustomization, string(data))
+	})
+
+	t.Run("fix kept when the build already fails", func(t *testing.T) {
+		// given
+		fixes := []validation.Fix{}
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/components/pasta/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- missing.yaml
+`,
+			// not a Kubernetes manifest
+			"/path/to/components/pasta/values.yaml": `replicas: 1`,
+		})
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithFix(&fixes))
+
+		// when
+		err := checker.CheckComponents("components")
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, []validation.Fix{
+			{
+				Path:    "components/pasta/kustomization.yaml",
+				Added:   []string{"values.yaml"},
+				Removed: []string{"missing.yaml"},
+			},
+		}, fixes)
+	})
+
+	t.Run("base directory", func(t *testing.T) {
+		// given
+		fixes := []validation.Fix{}
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/components/base/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- configmap.yaml
+`,
+			"/path/to/components/base/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: pasta`,
+			// the build of the base directories is not verified
+			"/path/to/components/base/values.yaml": `replicas: 1`,
+		})
+		checker := validation.NewChecker(logger, afs, "/path/to", validation.WithFix(&fixes))
+
+		// when
+		err := checker.CheckComponents("components")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []validation.Fix{
+			{
+				Path:    "components/base/kustomization.yaml",
+				Added:   []string{"values.yaml"},
+				Removed: []string{},
+			},
+		}, fixes)
+	})
+}
@@ -12,13 +12,41 @@ import (
 
 func NewInMemoryFS(logger *log.Logger, afs afero.Afero, baseDir string) (kfsys.FileSystem, error) {
 	fsys := kfsys.MakeFsInMemory()
-	if err := afs.Walk(baseDir,
+	if err := copyToFS(logger, afs, fsys, baseDir); err != nil {
+		return nil, err
+	}
+	return fsys, nil
+}
+
+// UpdateInMemoryFS copies the file or directory at the given path from the underlying filesystem into the in-memory filesystem,
+// or removes it from the in-memory filesystem if it does not exist anymore.
+func UpdateInMemoryFS(logger *log.Logger, afs afero.Afero, fsys kfsys.FileSystem, path string) error {
+	exists, err := afs.Exists(path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		logger.Debug("removing path from fsys", "path", path)
+		return fsys.RemoveAll(path)
+	}
+	if err := fsys.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return copyToFS(logger, afs, fsys, path)
+}
+
+func copyToFS(logger *log.Logger, afs afero.Afero, fsys kfsys.FileSystem, path string) error {
+	return afs.Walk(path,
 		func(path string, info iofs.FileInfo, err error) error {
 			if err != nil {
 				logger.Error("prevent panic by handling failure", "path", path, "err", err)
 				return err
 			}
 			if info.IsDir() {
+				if info.Name() == ".git" {
+					logger.Debug("skipping directory", "path", path)
+					return filepath.SkipDir
+				}
 				logger.Debug("adding directory in fsys", "path", path)
 				return fsys.Mkdir(path)
 			}
@@ -33,8 +61,5 @@ func NewInMemoryFS(logger *log.Logger, afs afero.Afero, baseDir string) (kfsys.F
 			logger.Debug("adding file in fsys", "path", path)
 			return fsys.WriteFile(path, data)
 		},
-	); err != nil {
-		return nil, err
-	}
-	return fsys, nil
+	)
 }
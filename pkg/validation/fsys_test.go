@@ -62,3 +62,66 @@ func TestNewInMemory(t *testing.T) {
 		assert.False(t, fsys.Exists("/basedir/apps/read me.md"))
 	})
 }
+
+func TestUpdateInMemory(t *testing.T) {
+
+	// given
+	logger := log.New(os.Stdout)
+	afs := afero.Afero{
+		Fs: afero.NewMemMapFs(),
+	}
+	err := afs.MkdirAll("/basedir/apps", 0755)
+	require.NoError(t, err)
+	err = afs.WriteFile("/basedir/apps/kustomization.yaml", []byte("cookies are yummy"), 0755)
+	require.NoError(t, err)
+	fsys, err := validation.NewInMemoryFS(logger, afs, "/basedir")
+	require.NoError(t, err)
+
+	t.Run("updated file", func(t *testing.T) {
+		// given
+		data := []byte("pasta is yummy")
+		err := afs.WriteFile("/basedir/apps/kustomization.yaml", data, 0755)
+		require.NoError(t, err)
+
+		// when
+		err = validation.UpdateInMemoryFS(logger, afs, fsys, "/basedir/apps/kustomization.yaml")
+
+		// then
+		require.NoError(t, err)
+		actual, err := fsys.ReadFile("/basedir/apps/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, data, actual)
+	})
+
+	t.Run("new directory", func(t *testing.T) {
+		// given
+		data := []byte("pizza is yummy")
+		err := afs.MkdirAll("/basedir/components/pizza", 0755)
+		require.NoError(t, err)
+		err = afs.WriteFile("/basedir/components/pizza/kustomization.yaml", data, 0755)
+		require.NoError(t, err)
+
+		// when
+		err = validation.UpdateInMemoryFS(logger, afs, fsys, "/basedir/components")
+
+		// then
+		require.NoError(t, err)
+		actual, err := fsys.ReadFile("/basedir/components/pizza/kustomization.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, data, actual)
+	})
+
+	t.Run("deleted file", func(t *testing.T) {
+		// given
+		err := afs.Remove("/basedir/apps/kustomization.yaml")
+		require.NoError(t, err)
+
+		// when
+		err = validation.UpdateInMemoryFS(logger, afs, fsys, "/basedir/apps/kustomization.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, fsys.Exists("/basedir/apps/kustomization.yaml"))
+		assert.True(t, fsys.Exists("/basedir/apps"))
+	})
+}
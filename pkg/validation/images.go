@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Verifies that the images of the workloads rendered by the given Application (or Application generated by an ApplicationSet),
+// and the images of its Kustomize overrides, follow the image policy. Also records the images in the inventory.
+// Images and overrides which contain a `{{...}}` placeholder are not checked.
+func (c *Checker) checkImages(path string, app *argocdv1alpha1.Application, objs []*unstructured.Unstructured) error {
+	rpath, _ := filepath.Rel(c.baseDir, path)
+	imgs := images.List(objs)
+	if c.imageInventory != nil {
+		*c.imageInventory = append(*c.imageInventory, images.Inventory{
+			Application: app.Name,
+			Path:        rpath,
+			Images:      imgs,
+		})
+	}
+	if c.imagePolicy == nil {
+		return nil
+	}
+	for _, source := range app.Spec.GetSources() {
+		if source.Kustomize == nil {
+			continue
+		}
+		for _, override := range source.Kustomize.Images {
+			ref := string(override)
+			if _, newRef, renamed := strings.Cut(ref, "="); renamed {
+				ref = newRef
+			}
+			if applications.IsTemplated(ref) {
+				continue
+			}
+			check := c.imagePolicy.Check
+			if r := images.Parse(ref); r.Tag == "" && r.Digest == "" {
+				// the override only changes the name of the image, whose tag is checked in the rendered workloads
+				check = c.imagePolicy.CheckRegistry
+			}
+			if err := check(ref); err != nil {
+				return fmt.Errorf("application '%s' in %s has an invalid Kustomize image override: %w", app.Name, rpath, err)
+			}
+		}
+	}
+	for _, img := range imgs {
+		if applications.IsTemplated(img.Reference) {
+			continue
+		}
+		if err := c.imagePolicy.Check(img.Reference); err != nil {
+			return fmt.Errorf("application '%s' in %s has an invalid image in %s (container '%s'): %w", app.Name, rpath, img.Workload, img.Container, err)
+		}
+	}
+	return nil
+}
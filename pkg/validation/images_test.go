@@ -0,0 +1,222 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckImages(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- deployment.yaml`,
+	}
+	policy := images.Policy{
+		AllowedRegistries: []string{"quay.io/codeready-toolchain"},
+	}
+
+	t.Run("valid image", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      images: []`,
+			"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/codeready-toolchain/cookie:v1.0.0`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithImagePolicy(policy)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("latest image fixed by override", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      images: [quay.io/codeready-toolchain/cookie:v1.0.1]`,
+			"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/codeready-toolchain/cookie:latest`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithImagePolicy(policy)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("latest image", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      images: []`,
+			"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/codeready-toolchain/cookie:latest`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithImagePolicy(policy)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has an invalid image in Deployment/cookie (container 'cookie'): image 'quay.io/codeready-toolchain/cookie:latest' uses the 'latest' tag")
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      images: [quay.io/codeready-toolchain/cookie=docker.io/cookie]`,
+			"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/codeready-toolchain/cookie:v1.0.0`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithImagePolicy(policy)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has an invalid Kustomize image override: image 'docker.io/cookie' is not from an allowed registry (quay.io/codeready-toolchain)")
+	})
+
+	t.Run("inventory", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: default
+  source:
+    path: components/cookie
+    kustomize:
+      images: []`,
+			"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/codeready-toolchain/cookie:main`,
+		})
+		inventory := []images.Inventory{}
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithImageInventory(&inventory)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []images.Inventory{
+			{
+				Application: "app-cookie",
+				Path:        "apps/app.yaml",
+				Images: []images.Image{
+					{
+						Reference: "quay.io/codeready-toolchain/cookie:main",
+						Workload:  "Deployment/cookie",
+						Container: "cookie",
+					},
+				},
+			},
+		}, inventory)
+	})
+}
@@ -1,34 +1,39 @@
 package validation
 
 import (
-	"bytes"
+	"fmt"
 	"path/filepath"
 
-	"github.com/charmbracelet/log"
-	"github.com/spf13/afero"
-	kbuild "sigs.k8s.io/kustomize/kustomize/v5/commands/build"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
-func lookupKustomizationFile(logger *log.Logger, afs afero.Afero, componentPath string) (string, bool) {
-	for _, k := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
-		p := filepath.Join(componentPath, k)
-		if _, err := afs.Open(p); err == nil {
-			logger.Debug("found Kustomization file", "path", p)
-			return p, true
+// Verifies that `kustomize build` completes successfully, that the Argo CD annotations
+// on the resulting objects are valid, that the objects do not use a removed API version and that they do not
+// contain sensitive data
+func (c *Checker) checkBuild(fsys kfsys.FileSystem, path string) error {
+	c.logger.Debug("👀 checking kustomize build", "path", path)
+	build := render.Kustomize
+	if c.secrets {
+		// keep track of the origin of the objects, to skip the sensitive data declared in the allowed files
+		build = func(fsys kfsys.FileSystem, path string) ([]*unstructured.Unstructured, error) {
+			return render.KustomizeWithOrigins(c.logger, fsys, path)
 		}
 	}
-	return "", false
-}
-
-// Verifies that `kustomize build` completes successfully
-func checkBuild(logger *log.Logger, fsys kfsys.FileSystem, path string) error {
-	logger.Debug("👀 checking kustomize build", "path", path)
-	buffy := new(bytes.Buffer)
-
-	kcmd := kbuild.NewCmdBuild(fsys, &kbuild.Help{}, buffy)
-	if err := kcmd.RunE(kcmd, []string{path}); err != nil {
+	objs, err := build(fsys, path)
+	if err != nil {
+		return err
+	}
+	rpath, _ := filepath.Rel(c.baseDir, path)
+	for _, obj := range objs {
+		if err := checkSyncAnnotations(obj); err != nil {
+			return fmt.Errorf("%s/%s in %s: %w", obj.GetKind(), obj.GetName(), rpath, err)
+		}
+	}
+	if err := c.checkAPIVersions(rpath, objs); err != nil {
 		return err
 	}
-	return nil
+	return c.checkRenderedSecrets(path, objs)
 }
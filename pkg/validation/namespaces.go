@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Verifies the namespaces of the rendered manifests of the given Application (or Application generated by an ApplicationSet):
+// - namespaced objects are in the destination namespace (when it is set),
+// - namespaced objects have a namespace when the destination does not set one,
+// - cluster-scoped objects are not part of an Application whose project only permits namespaced resources
+// (when the projects are known).
+// Destinations whose namespace contains a `{{...}}` placeholder are not checked.
+func (c *Checker) checkNamespaces(path string, app *argocdv1alpha1.Application, objs []*unstructured.Unstructured) error {
+	dest := app.Spec.Destination
+	if applications.IsTemplated(dest.Namespace) {
+		return nil
+	}
+	rpath, _ := filepath.Rel(c.baseDir, path)
+	var proj *argocdv1alpha1.AppProject
+	if c.projects != nil {
+		proj, _ = c.project(app)
+	}
+	s := newScope(objs)
+	for _, obj := range objs {
+		if !s.namespaced(obj) {
+			if proj != nil && len(proj.Spec.ClusterResourceWhitelist) == 0 {
+				return fmt.Errorf("application '%s' in %s has a cluster-scoped resource '%s/%s' but project '%s' only permits namespaced resources",
+					app.Name, rpath, obj.GetKind(), obj.GetName(), proj.Name)
+			}
+			continue
+		}
+		switch {
+		case obj.GetNamespace() == "" && dest.Namespace == "":
+			return fmt.Errorf("application '%s' in %s has a resource without namespace '%s/%s' but its destination does not set a namespace",
+				app.Name, rpath, obj.GetKind(), obj.GetName())
+		case obj.GetNamespace() != "" && dest.Namespace != "" && obj.GetNamespace() != dest.Namespace:
+			return fmt.Errorf("application '%s' in %s has a resource '%s/%s' in namespace '%s' instead of the destination namespace '%s'",
+				app.Name, rpath, obj.GetKind(), obj.GetName(), obj.GetNamespace(), dest.Namespace)
+		}
+	}
+	return nil
+}
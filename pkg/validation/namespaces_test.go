@@ -0,0 +1,214 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckNamespaces(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- resources.yaml`,
+	}
+	sandbox := &argocdv1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sandbox",
+		},
+		Spec: argocdv1alpha1.AppProjectSpec{
+			SourceRepos: []string{"*"},
+			Destinations: []argocdv1alpha1.ApplicationDestination{
+				{
+					Server:    "*",
+					Namespace: "*",
+				},
+			},
+		},
+	}
+
+	t.Run("same namespace", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true`,
+			"/path/to/components/cookie/resources.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: cookie
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces()).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("no namespace with destination namespace", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true`,
+			"/path/to/components/cookie/resources.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: ""`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces()).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("different namespace", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true`,
+			"/path/to/components/cookie/resources.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: pasta`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces()).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has a resource 'Deployment/cookie' in namespace 'pasta' instead of the destination namespace 'cookie'")
+	})
+
+	t.Run("no namespace without destination namespace", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: ""
+  project: sandbox
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true`,
+			"/path/to/components/cookie/resources.yaml": `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: ""`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces()).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has a resource without namespace 'Deployment/cookie' but its destination does not set a namespace")
+	})
+
+	t.Run("cluster-scoped resource in namespace-only project", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true`,
+			"/path/to/components/cookie/resources.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: cookie
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithNamespaces(), validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml has a cluster-scoped resource 'ClusterRole/cookie' but project 'sandbox' only permits namespaced resources")
+	})
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// the `default` project which is created by Argo CD when it does not exist, and which permits everything
+var defaultProject = &argocdv1alpha1.AppProject{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "default",
+	},
+	Spec: argocdv1alpha1.AppProjectSpec{
+		SourceRepos: []string{"*"},
+		Destinations: []argocdv1alpha1.ApplicationDestination{
+			{
+				Server:    "*",
+				Name:      "*",
+				Namespace: "*",
+			},
+		},
+		ClusterResourceWhitelist: []metav1.GroupKind{
+			{
+				Group: "*",
+				Kind:  "*",
+			},
+		},
+	},
+}
+
+// Verifies that the given Application (or Application generated by an ApplicationSet) is permitted by its AppProject:
+// - the project exists,
+// - the repository of each source is one of the project's `sourceRepos`,
+// - the destination is one of the project's `destinations`,
+// - the kinds of the rendered manifests are permitted by the project's cluster/namespace resource allow/deny lists,
+// and that the namespaces of the rendered manifests are permitted destinations.
+func (c *Checker) checkProject(path string, app *argocdv1alpha1.Application, objs []*unstructured.Unstructured) error {
+	rpath, _ := filepath.Rel(c.baseDir, path)
+	proj, found := c.project(app)
+	if !found {
+		return fmt.Errorf("application '%s' in %s refers to an unknown project: '%s'", app.Name, rpath, app.Spec.Project)
+	}
+	notPermitted := func(msg string, args ...interface{}) error {
+		return fmt.Errorf("application '%s' in %s is not permitted by project '%s': %s", app.Name, rpath, proj.Name, fmt.Sprintf(msg, args...))
+	}
+	// project-scoped clusters can't be verified offline
+	projectClusters := func(_ string) ([]*argocdv1alpha1.Cluster, error) {
+		return []*argocdv1alpha1.Cluster{
+			{
+				Server: app.Spec.Destination.Server,
+				Name:   app.Spec.Destination.Name,
+			},
+		}, nil
+	}
+
+	for _, source := range app.Spec.GetSources() {
+		if applications.IsTemplated(source.RepoURL) {
+			continue
+		}
+		if !proj.IsSourcePermitted(source) {
+			return notPermitted("source repository '%s' is not allowed", source.RepoURL)
+		}
+	}
+	dest := app.Spec.Destination
+	if !applications.IsTemplated(dest.Server) && !applications.IsTemplated(dest.Name) && !applications.IsTemplated(dest.Namespace) {
+		if permitted, err := proj.IsDestinationPermitted(dest, projectClusters); err != nil {
+			return err
+		} else if !permitted {
+			return notPermitted("destination '%s' with namespace '%s' is not allowed", destinationName(dest), dest.Namespace)
+		}
+	}
+
+	s := newScope(objs)
+	for _, obj := range objs {
+		gk := obj.GroupVersionKind().GroupKind()
+		namespaced := s.namespaced(obj)
+		if !proj.IsGroupKindPermitted(gk, namespaced) {
+			if namespaced {
+				return notPermitted("namespaced resource '%s/%s' is not allowed", obj.GetKind(), obj.GetName())
+			}
+			return notPermitted("cluster-scoped resource '%s/%s' is not allowed", obj.GetKind(), obj.GetName())
+		}
+		if namespaced && obj.GetNamespace() != "" && obj.GetNamespace() != dest.Namespace {
+			d := argocdv1alpha1.ApplicationDestination{
+				Server:    dest.Server,
+				Name:      dest.Name,
+				Namespace: obj.GetNamespace(),
+			}
+			if permitted, err := proj.IsDestinationPermitted(d, projectClusters); err != nil {
+				return err
+			} else if !permitted {
+				return notPermitted("resource '%s/%s' in namespace '%s' is not allowed", obj.GetKind(), obj.GetName(), obj.GetNamespace())
+			}
+		}
+	}
+	return nil
+}
+
+// returns the AppProject of the given Application, or the default project if the Application does not specify one
+// (or if it specifies the `default` project, which is not declared)
+func (c *Checker) project(app *argocdv1alpha1.Application) (*argocdv1alpha1.AppProject, bool) {
+	name := app.Spec.Project
+	if name == "" {
+		name = defaultProject.Name
+	}
+	if proj, found := c.projects[name]; found {
+		return proj, true
+	}
+	if name == defaultProject.Name {
+		return defaultProject, true
+	}
+	return nil, false
+}
+
+// returns the server URL or the name of the destination cluster
+func destinationName(dest argocdv1alpha1.ApplicationDestination) string {
+	if dest.Server != "" {
+		return dest.Server
+	}
+	return dest.Name
+}
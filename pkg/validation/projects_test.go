@@ -0,0 +1,371 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckApplicationsWithProjects(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app.yaml`,
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- deployment.yaml
+- clusterrole.yaml`,
+		"/path/to/components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: cookie`,
+		"/path/to/components/cookie/clusterrole.yaml": `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cookie`,
+	}
+
+	sandbox := &argocdv1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sandbox",
+		},
+		Spec: argocdv1alpha1.AppProjectSpec{
+			SourceRepos: []string{"https://github.com/codeready-toolchain/*"},
+			Destinations: []argocdv1alpha1.ApplicationDestination{
+				{
+					Server:    "https://kubernetes.default.svc",
+					Namespace: "cookie",
+				},
+			},
+			ClusterResourceWhitelist: []metav1.GroupKind{
+				{
+					Group: "rbac.authorization.k8s.io",
+					Kind:  "ClusterRole",
+				},
+			},
+		},
+	}
+	namespaceOnly := &argocdv1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "namespace-only",
+		},
+		Spec: argocdv1alpha1.AppProjectSpec{
+			SourceRepos: []string{"*"},
+			Destinations: []argocdv1alpha1.ApplicationDestination{
+				{
+					Server:    "*",
+					Namespace: "*",
+				},
+			},
+		},
+	}
+
+	t.Run("permitted", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("default project", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: pasta
+  project: default
+  source:
+    repoURL: https://github.com/somewhere/else
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects()).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbx
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml refers to an unknown project: 'sandbx'")
+	})
+
+	t.Run("unknown project with projects from the repository", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: pasta
+  project: cluster-only
+  source:
+    repoURL: https://github.com/somewhere/else
+    path: components/broken`,
+			// the Application is not rendered
+			"/path/to/components/broken/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- missing.yaml`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithRepositoryProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("known project with projects from the repository", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    repoURL: https://github.com/somewhere/else
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithRepositoryProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml is not permitted by project 'sandbox': source repository 'https://github.com/somewhere/else' is not allowed")
+	})
+
+	t.Run("source repository not permitted", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    repoURL: https://github.com/somewhere/else
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml is not permitted by project 'sandbox': source repository 'https://github.com/somewhere/else' is not allowed")
+	})
+
+	t.Run("destination not permitted", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: pasta
+  project: sandbox
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml is not permitted by project 'sandbox': destination 'https://kubernetes.default.svc' with namespace 'pasta' is not allowed")
+	})
+
+	t.Run("cluster-scoped resource not permitted", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: namespace-only
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(namespaceOnly)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml is not permitted by project 'namespace-only': cluster-scoped resource 'ClusterRole/cookie' is not allowed")
+	})
+
+	t.Run("resource namespace not permitted", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie`,
+		})
+		err := addFile(afs, "/path/to/components/cookie/deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+  namespace: pasta`)
+		require.NoError(t, err)
+
+		// when
+		err = validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml is not permitted by project 'sandbox': resource 'Deployment/cookie' in namespace 'pasta' is not allowed")
+	})
+
+	t.Run("appset with list generator", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: ApplicationSet
+metadata:
+  name: appset-cookie
+spec:
+  generators:
+  - list:
+      elements:
+      - namespace: cookie
+      - namespace: pasta
+  template:
+    metadata:
+      name: 'cookie-{{namespace}}'
+    spec:
+      destination:
+        server: https://kubernetes.default.svc
+        namespace: '{{namespace}}'
+      project: sandbox
+      source:
+        repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+        path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'cookie-pasta' in apps/app.yaml is not permitted by project 'sandbox': destination 'https://kubernetes.default.svc' with namespace 'pasta' is not allowed")
+	})
+	t.Run("multi-source application", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: cookie
+  project: sandbox
+  sources:
+  - repoURL: https://charts.example.com
+    chart: cookie
+    targetRevision: 1.0.0
+  - repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithProjects(sandbox)).CheckApplications("apps")
+
+		// then
+		require.EqualError(t, err, "application 'app-cookie' in apps/app.yaml is not permitted by project 'sandbox': source repository 'https://charts.example.com' is not allowed")
+	})
+}
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/suggest"
+
 	"github.com/charmbracelet/log"
 	"github.com/spf13/afero"
 	"sigs.k8s.io/kustomize/api/types"
@@ -15,21 +18,32 @@ import (
 // Files starting with an underscore character (`_`) are ignored
 func checkKustomizeResources(logger *log.Logger, afs afero.Afero, basedir, kpath string) error {
 	logger.Debug("checking kustomization resource", "path", kpath)
-	data, err := afs.ReadFile(kpath)
+	kobj, err := kustomizations.Read(afs, kpath)
 	if err != nil {
 		return err
 	}
-	var kobj types.Kustomization
-	if err := kobj.Unmarshal(data); err != nil {
+	names, err := unreferencedResources(logger, afs, kpath, kobj)
+	if err != nil {
 		return err
 	}
+	if len(names) > 0 {
+		rkpath, _ := filepath.Rel(basedir, kpath)
+		return fmt.Errorf("resource is not referenced in %s: %s", rkpath, names[0])
+	}
+	return checkKustomizeReferences(logger, afs, basedir, kpath, kobj)
+}
 
+// returns the names of the local YAML files and directories next to the Kustomize file which are not referenced in it
+// (as a resource, a component, a generator file, a patch, a replacement, etc. See `kustomizations.LocalReferences`), sorted
+func unreferencedResources(logger *log.Logger, afs afero.Afero, kpath string, kobj *types.Kustomization) ([]string, error) {
+	refs := kustomizations.LocalReferences(kobj)
 	// list resources
 	logger.Debug("checking kustomization resources", "dir", filepath.Dir(kpath))
 	entries, err := afs.ReadDir(filepath.Dir(kpath))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	names := []string{}
 entries:
 	for _, e := range entries {
 		name := e.Name()
@@ -48,51 +62,41 @@ entries:
 			continue entries
 		}
 
-		for _, r := range kobj.Resources {
-			if filepath.Clean(r) == name {
+		for _, r := range refs {
+			// the entry itself, or a file in the entry (eg: `patches/deployment.yaml` in the `patches` directory)
+			if r == name || strings.HasPrefix(r, name+string(filepath.Separator)) {
 				continue entries
 			}
 		}
-		for _, sg := range kobj.ConfigMapGenerator {
-			for _, f := range sg.FileSources {
-				if i := strings.LastIndex(f, "="); i > 0 {
-					if f[i+1:] == name {
-						continue entries
-					}
-				} else if f == name {
-					continue entries
-				}
-			}
-		}
-		for _, sg := range kobj.SecretGenerator {
-			for _, f := range sg.FileSources {
-				if i := strings.LastIndex(f, "="); i > 0 {
-					if f[i+1:] == name {
-						continue entries
-					}
-				} else if f == name {
-					continue entries
-				}
-			}
-		}
-		for _, m := range kobj.PatchesStrategicMerge { //nolint:staticcheck
-			if string(m) == name {
-				continue entries
-			}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Verifies that all local files and directories referenced in the Kustomize file exist.
+// Remote references (eg: `https://github.com/...`) are ignored.
+func checkKustomizeReferences(logger *log.Logger, afs afero.Afero, basedir, kpath string, kobj *types.Kustomization) error {
+	dir := filepath.Dir(kpath)
+	for _, r := range kustomizations.LocalReferences(kobj) {
+		p := filepath.Join(dir, r)
+		if exists, err := afs.Exists(p); err != nil {
+			return err
+		} else if exists {
+			continue
 		}
-		for _, m := range kobj.Patches {
-			if string(m.Path) == name {
-				continue entries
+		logger.Debug("referenced resource does not exist", "path", p)
+		rkpath, _ := filepath.Rel(basedir, kpath)
+		// look for similar entries in the parent dir of the missing resource
+		candidates := []string{}
+		if entries, err := afs.ReadDir(filepath.Dir(p)); err == nil {
+			for _, e := range entries {
+				candidates = append(candidates, filepath.Join(filepath.Dir(r), e.Name()))
 			}
 		}
-		for _, m := range kobj.Transformers {
-			if string(m) == name {
-				continue entries
-			}
+		if suggestions := suggest.Closest(r, candidates...); len(suggestions) > 0 {
+			return fmt.Errorf("resource referenced in %s does not exist: %s (did you mean: %s?)", rkpath, r, strings.Join(suggestions, ", "))
 		}
-		rkpath, _ := filepath.Rel(basedir, kpath)
-
-		return fmt.Errorf("resource is not referenced in %s: %s", rkpath, name)
+		return fmt.Errorf("resource referenced in %s does not exist: %s", rkpath, r)
 	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// well-known kinds of cluster-scoped resources in Kubernetes and OpenShift
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:                                                    true,
+	{Group: "", Kind: "Node"}:                                                         true,
+	{Group: "", Kind: "PersistentVolume"}:                                             true,
+	{Group: "", Kind: "ComponentStatus"}:                                              true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                         true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                  true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:                 true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                             true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:     true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicy"}:        true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicyBinding"}: true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                   true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                      true,
+	{Group: "storage.k8s.io", Kind: "CSINode"}:                                        true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                               true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                               true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                      true,
+	{Group: "networking.k8s.io", Kind: "IngressClass"}:                                true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                      true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:                 true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                       true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:       true,
+	{Group: "snapshot.storage.k8s.io", Kind: "VolumeSnapshotClass"}:                   true,
+	{Group: "snapshot.storage.k8s.io", Kind: "VolumeSnapshotContent"}:                 true,
+	{Group: "config.openshift.io", Kind: "ClusterVersion"}:                            true,
+	{Group: "config.openshift.io", Kind: "ClusterOperator"}:                           true,
+	{Group: "config.openshift.io", Kind: "OAuth"}:                                     true,
+	{Group: "project.openshift.io", Kind: "Project"}:                                  true,
+	{Group: "project.openshift.io", Kind: "ProjectRequest"}:                           true,
+	{Group: "security.openshift.io", Kind: "SecurityContextConstraints"}:              true,
+	{Group: "user.openshift.io", Kind: "User"}:                                        true,
+	{Group: "user.openshift.io", Kind: "Group"}:                                       true,
+	{Group: "user.openshift.io", Kind: "Identity"}:                                    true,
+	{Group: "quota.openshift.io", Kind: "ClusterResourceQuota"}:                       true,
+	{Group: "console.openshift.io", Kind: "ConsoleLink"}:                              true,
+	{Group: "console.openshift.io", Kind: "ConsoleNotification"}:                      true,
+	{Group: "console.openshift.io", Kind: "ConsolePlugin"}:                            true,
+	{Group: "cert-manager.io", Kind: "ClusterIssuer"}:                                 true,
+	{Group: "machineconfiguration.openshift.io", Kind: "MachineConfig"}:               true,
+	{Group: "machineconfiguration.openshift.io", Kind: "MachineConfigPool"}:           true,
+}
+
+// scope of the resources, including the Custom Resources whose definition is part of the rendered manifests
+type scope struct {
+	clusterScopedKinds map[schema.GroupKind]bool
+}
+
+// returns the scope of the well-known kinds and of the kinds defined in the CRDs in the given objects
+func newScope(objs []*unstructured.Unstructured) scope {
+	s := scope{
+		clusterScopedKinds: map[schema.GroupKind]bool{},
+	}
+	for gk, clusterScoped := range clusterScopedKinds {
+		s.clusterScopedKinds[gk] = clusterScoped
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+		s.clusterScopedKinds[schema.GroupKind{Group: group, Kind: kind}] = scope == "Cluster"
+	}
+	return s
+}
+
+// returns true if the given object is namespaced (ie: not cluster-scoped).
+// Objects of unknown kinds are considered namespaced
+func (s scope) namespaced(obj *unstructured.Unstructured) bool {
+	return !s.clusterScopedKinds[obj.GroupVersionKind().GroupKind()]
+}
+
+// Namespaced returns a function which tells if an object is namespaced, based on the well-known cluster-scoped kinds
+// and on the CustomResourceDefinitions in the given objects (objects of unknown kinds are considered namespaced)
+func Namespaced(objs []*unstructured.Unstructured) func(*unstructured.Unstructured) bool {
+	return newScope(objs).namespaced
+}
@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/render"
+
+	"github.com/argoproj/gitops-engine/pkg/utils/kube"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kfsys "sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// minimum length of a string to be checked for its entropy
+	minSecretLength = 20
+	// entropy (in bits per character) above which a long base64 string looks like a secret
+	base64EntropyThreshold = 4.5
+	// entropy (in bits per character) above which a long hexadecimal string looks like a secret
+	hexEntropyThreshold = 3.0
+	// ratio of the maximum entropy of a word of a given length (ie: `log2(length)`, when all characters are distinct)
+	// above which a short string looks like a secret, since the thresholds above are out of reach for short words
+	entropyRatio = 0.85
+)
+
+var (
+	// separators of the words in a ConfigMap value
+	wordSeparator = regexp.MustCompile(`[^A-Za-z0-9+/=_-]+`)
+	hexWord       = regexp.MustCompile(`^[A-Fa-f0-9]+$`)
+	// SHA-1, SHA-256 and SHA-512 digests (eg: Git commit SHAs or the `sha256:...` digests of images)
+	hexDigest = regexp.MustCompile(`^([A-Fa-f0-9]{40}|[A-Fa-f0-9]{64}|[A-Fa-f0-9]{128})$`)
+)
+
+// Verifies that the YAML files in the given directory of the in-memory filesystem do not contain sensitive data
+// (see `checkSensitiveData`). Subdirectories are not checked.
+func (c *Checker) checkSecretFiles(fsys kfsys.FileSystem, dir string) error {
+	if !c.secrets {
+		return nil
+	}
+	names, err := fsys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if ext := filepath.Ext(name); fsys.IsDir(path) || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		rpath, _ := filepath.Rel(c.baseDir, path)
+		if c.secretAllowed(rpath) {
+			c.logger.Debug("skipping allowed file", "path", rpath)
+			continue
+		}
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		objs, err := kube.SplitYAML(data)
+		if err != nil {
+			// not a Kubernetes manifest (eg: Helm values)
+			c.logger.Debug("skipping file", "path", rpath, "err", err)
+			continue
+		}
+		if err := checkSensitiveData(objs); err != nil {
+			return fmt.Errorf("sensitive data in %s: %w", rpath, err)
+		}
+	}
+	return nil
+}
+
+// Verifies that the objects rendered (with their origin) from the kustomization in the given directory
+// do not contain sensitive data (see `checkSensitiveData`), unless they were declared in an allowed file
+// (or generated by an allowed kustomization)
+func (c *Checker) checkRenderedSecrets(path string, objs []*unstructured.Unstructured) error {
+	if !c.secrets {
+		return nil
+	}
+	rpath, _ := filepath.Rel(c.baseDir, path)
+	checked := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if origin, found := render.Origin(obj); found {
+			if ropath, _ := filepath.Rel(c.baseDir, filepath.Join(path, origin)); c.secretAllowed(ropath) {
+				continue
+			}
+		}
+		checked = append(checked, obj)
+	}
+	if err := checkSensitiveData(checked); err != nil {
+		return fmt.Errorf("sensitive data in the output of %s: %w", rpath, err)
+	}
+	return nil
+}
+
+// returns true if the given path (or one of its parent directories) matches one of the allowed patterns
+func (c *Checker) secretAllowed(path string) bool {
+	for p := filepath.Clean(path); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		for _, pattern := range c.allowedSecrets {
+			if matched, _ := filepath.Match(pattern, p); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Verifies that there is no Secret with `data` or `stringData`, and no ConfigMap with a high-entropy value.
+// Encrypted or externalized secrets (eg: SealedSecrets and ExternalSecrets) are not affected.
+func checkSensitiveData(objs []*unstructured.Unstructured) error {
+	for _, obj := range objs {
+		switch obj.GetKind() {
+		case "Secret":
+			for _, field := range []string{"data", "stringData"} {
+				if values, found, _ := unstructured.NestedMap(obj.Object, field); found && len(values) > 0 {
+					return fmt.Errorf("secret '%s' has '%s' (use a SealedSecret or an ExternalSecret instead)", obj.GetName(), field)
+				}
+			}
+		case "ConfigMap":
+			values, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+			keys := make([]string, 0, len(values))
+			for k := range values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if highEntropy(values[k]) {
+					return fmt.Errorf("configmap '%s' has a high-entropy value for key '%s' (use a SealedSecret or an ExternalSecret instead)", obj.GetName(), k)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// returns true if the given value contains a long base64 or hexadecimal word with a high entropy
+// (ie: which looks like a password, a token or a key).
+// Hexadecimal digests are not considered as secrets.
+func highEntropy(value string) bool {
+	for _, w := range wordSeparator.Split(value, -1) {
+		if len(w) < minSecretLength || hexDigest.MatchString(w) {
+			continue
+		}
+		threshold := base64EntropyThreshold
+		if hexWord.MatchString(w) {
+			threshold = hexEntropyThreshold
+		}
+		// scale the threshold to the maximum entropy of a word of this length
+		threshold = math.Min(threshold, entropyRatio*math.Log2(float64(len(w))))
+		if entropy(w) > threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the Shannon entropy of the given string, in bits per character
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	e := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(len(s))
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// returns the given patterns without surrounding spaces and empty values
+func cleanPatterns(patterns []string) []string {
+	result := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, filepath.Clean(p))
+		}
+	}
+	return result
+}
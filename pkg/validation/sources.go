@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// SourceExpectation the repository URL and target revision that the Applications and ApplicationSets
+// in a directory are expected to use (unset values are not checked)
+type SourceExpectation struct {
+	// Path to the directory, relative to the base directory
+	Path           string `json:"path"`
+	RepoURL        string `json:"repoURL,omitempty"`
+	TargetRevision string `json:"targetRevision,omitempty"`
+}
+
+// ReadSourceExpectations reads the list of expectations in the given YAML file
+func ReadSourceExpectations(afs afero.Afero, path string) ([]SourceExpectation, error) {
+	data, err := afs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	expectations := []SourceExpectation{}
+	if err := yaml.UnmarshalStrict(data, &expectations); err != nil {
+		return nil, fmt.Errorf("invalid source expectations in %s: %w", path, err)
+	}
+	return expectations, nil
+}
+
+// a repository URL and target revision, and the Applications and ApplicationSets which use it
+type sourceGroup struct {
+	repoURL        string
+	targetRevision string
+	names          []string
+}
+
+// CheckSources verifies that the sources of the Applications and ApplicationSets in the directories of the expectations
+// use the expected repository URL and target revision. Applications in nested directories are only checked
+// against the expectation of the most specific directory (see `applications.WalkApplications`).
+// Sources of Helm charts and values which contain a `{{...}}` placeholder are not checked.
+func (c *Checker) CheckSources() error {
+	expectations := make([]SourceExpectation, len(c.expectations))
+	copy(expectations, c.expectations)
+	sort.SliceStable(expectations, func(i, j int) bool {
+		return len(filepath.Clean(expectations[i].Path)) > len(filepath.Clean(expectations[j].Path))
+	})
+	checked := map[string]bool{}
+	for _, e := range expectations {
+		c.logger.Info("👀 checking sources of Applications and ApplicationSets", "path", e.Path)
+		dir := filepath.Join(c.baseDir, e.Path)
+		if exists, err := c.afs.DirExists(dir); err != nil {
+			return err
+		} else if !exists {
+			return fmt.Errorf("directory of source expectation does not exist: %s", e.Path)
+		}
+		groups := map[string]*sourceGroup{}
+		if err := applications.WalkApplications(c.logger, c.afs, dir, func(path string, app *argocdv1alpha1.Application, appset *argocdv1alpha1.ApplicationSet) {
+			switch {
+			case app != nil && !checked[path+"/Application/"+app.Name]:
+				checked[path+"/Application/"+app.Name] = true
+				addSourceGroups(groups, app.Name, app.Spec.GetSources())
+			case appset != nil && !checked[path+"/ApplicationSet/"+appset.Name]:
+				checked[path+"/ApplicationSet/"+appset.Name] = true
+				addSourceGroups(groups, appset.Name, appset.Spec.Template.Spec.GetSources())
+			}
+		}); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(groups))
+		for k := range groups {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			g := groups[k]
+			c.logger.Debug("found sources", "path", e.Path, "repoURL", g.repoURL, "targetRevision", g.targetRevision, "count", len(g.names))
+			if e.RepoURL != "" && !applications.IsTemplated(g.repoURL) && normalizeRepoURL(g.repoURL) != normalizeRepoURL(e.RepoURL) {
+				return fmt.Errorf("unexpected repoURL in %s: '%s' in %s (expected '%s')", e.Path, g.repoURL, strings.Join(g.names, ", "), e.RepoURL)
+			}
+			if e.TargetRevision != "" && !applications.IsTemplated(g.targetRevision) && g.targetRevision != e.TargetRevision {
+				return fmt.Errorf("unexpected targetRevision in %s: '%s' in %s (expected '%s')", e.Path, g.targetRevision, strings.Join(g.names, ", "), e.TargetRevision)
+			}
+		}
+	}
+	return nil
+}
+
+// adds the given sources to the groups with the same repository URL and target revision.
+// Sources of Helm charts are ignored since they don't come from the Git repository.
+func addSourceGroups(groups map[string]*sourceGroup, name string, sources argocdv1alpha1.ApplicationSources) {
+	for _, s := range sources {
+		if s.Chart != "" {
+			continue
+		}
+		revision := s.TargetRevision
+		if revision == "" {
+			// default value in Argo CD
+			revision = "HEAD"
+		}
+		key := s.RepoURL + "@" + revision
+		g, found := groups[key]
+		if !found {
+			g = &sourceGroup{
+				repoURL:        s.RepoURL,
+				targetRevision: revision,
+			}
+			groups[key] = g
+		}
+		if len(g.names) == 0 || g.names[len(g.names)-1] != name {
+			g.names = append(g.names, name)
+		}
+	}
+}
+
+// normalizes the given repository URL, so that `https://github.com/org/repo.git` and `https://github.com/org/repo`
+// are considered equal
+func normalizeRepoURL(url string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(url), "/"), ".git")
+}
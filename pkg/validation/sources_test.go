@@ -0,0 +1,368 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSources(t *testing.T) {
+
+	// given
+	expectations := []validation.SourceExpectation{
+		{
+			Path:           "apps",
+			RepoURL:        "https://github.com/codeready-toolchain/sandbox-argocd",
+			TargetRevision: "HEAD",
+		},
+		{
+			Path:           "apps/prod",
+			TargetRevision: "v1.0.0",
+		},
+	}
+
+	t.Run("expected sources", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd.git
+    targetRevision: HEAD
+    path: components/cookie`,
+			"/path/to/apps/pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: ""
+    path: components/pasta`,
+			"/path/to/apps/prod/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: v1.0.0
+    path: components/prod-cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("unexpected target revision", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: my-test-branch
+    path: components/cookie`,
+			"/path/to/apps/pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+    path: components/pasta`,
+			"/path/to/apps/prod/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: v1.0.0
+    path: components/prod-cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.EqualError(t, err, "unexpected targetRevision in apps: 'my-test-branch' in cookie (expected 'HEAD')")
+	})
+
+	t.Run("unexpected target revision of application with destination name", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    name: member-1
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: my-test-branch
+    path: components/cookie`,
+			"/path/to/apps/prod/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-cookie
+spec:
+  destination:
+    name: member-1
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: v1.0.0
+    path: components/prod-cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.EqualError(t, err, "unexpected targetRevision in apps: 'my-test-branch' in cookie (expected 'HEAD')")
+	})
+
+	t.Run("unexpected target revision of application with the same name in another file", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+    path: components/cookie`,
+			"/path/to/apps/team/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: my-test-branch
+    path: components/team-cookie`,
+			"/path/to/apps/prod/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: v1.0.0
+    path: components/prod-cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.EqualError(t, err, "unexpected targetRevision in apps: 'my-test-branch' in cookie (expected 'HEAD')")
+	})
+
+	t.Run("unexpected target revision in nested directory", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+    path: components/cookie`,
+			"/path/to/apps/prod/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+    path: components/prod-cookie`,
+			"/path/to/apps/prod/pasta.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-pasta
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+    path: components/prod-pasta`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.EqualError(t, err, "unexpected targetRevision in apps/prod: 'HEAD' in prod-cookie, prod-pasta (expected 'v1.0.0')")
+	})
+
+	t.Run("unexpected repo URL", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/somebody/sandbox-argocd
+    targetRevision: HEAD
+    path: components/cookie`,
+			"/path/to/apps/prod/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: prod-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: v1.0.0
+    path: components/prod-cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.EqualError(t, err, "unexpected repoURL in apps: 'https://github.com/somebody/sandbox-argocd' in cookie (expected 'https://github.com/codeready-toolchain/sandbox-argocd')")
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, map[string]string{
+			"/path/to/apps/cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+    targetRevision: HEAD
+    path: components/cookie`,
+		})
+
+		// when
+		err := validation.NewChecker(logger, afs, "/path/to", validation.WithSourceExpectations(expectations...)).CheckSources()
+
+		// then
+		require.EqualError(t, err, "directory of source expectation does not exist: apps/prod")
+	})
+}
+
+func TestReadSourceExpectations(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := addFile(afs, "/path/to/expected-sources.yaml", `- path: apps
+  repoURL: https://github.com/codeready-toolchain/sandbox-argocd
+  targetRevision: HEAD`)
+		require.NoError(t, err)
+
+		// when
+		expectations, err := validation.ReadSourceExpectations(afs, "/path/to/expected-sources.yaml")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []validation.SourceExpectation{
+			{
+				Path:           "apps",
+				RepoURL:        "https://github.com/codeready-toolchain/sandbox-argocd",
+				TargetRevision: "HEAD",
+			},
+		}, expectations)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		// given
+		afs := afero.Afero{
+			Fs: afero.NewMemMapFs(),
+		}
+		err := addFile(afs, "/path/to/expected-sources.yaml", `- path: apps
+  revision: HEAD`)
+		require.NoError(t, err)
+
+		// when
+		_, err = validation.ReadSourceExpectations(afs, "/path/to/expected-sources.yaml")
+
+		// then
+		require.ErrorContains(t, err, "invalid source expectations in /path/to/expected-sources.yaml: ")
+	})
+}
@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/applications"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/suggest"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	syncOptionsAnnotation      = "argocd.argoproj.io/sync-options"
+	syncWaveAnnotation         = "argocd.argoproj.io/sync-wave"
+	hookAnnotation             = "argocd.argoproj.io/hook"
+	hookDeletePolicyAnnotation = "argocd.argoproj.io/hook-delete-policy"
+	compareOptionsAnnotation   = "argocd.argoproj.io/compare-options"
+)
+
+// the sync options supported by Argo CD (on Applications and resources), with their accepted values
+var syncOptions = map[string][]string{
+	"Prune":                       {"true", "false", "confirm"},
+	"Delete":                      {"true", "false", "confirm"},
+	"Validate":                    {"true", "false"},
+	"SkipDryRunOnMissingResource": {"true", "false"},
+	"Replace":                     {"true", "false"},
+	"ServerSideApply":             {"true", "false"},
+	"Force":                       {"true", "false"},
+	"PruneLast":                   {"true", "false"},
+	"CreateNamespace":             {"true", "false"},
+	"ApplyOutOfSyncOnly":          {"true", "false"},
+	"PrunePropagationPolicy":      {"foreground", "background", "orphan"},
+	"RespectIgnoreDifferences":    {"true", "false"},
+	"FailOnSharedResource":        {"true", "false"},
+}
+
+// the compare options supported by Argo CD, with their accepted values (nil if the option has no value)
+var compareOptions = map[string][]string{
+	"IgnoreExtraneous":       nil,
+	"ServerSideDiff":         {"true", "false"},
+	"IncludeMutationWebhook": {"true", "false"},
+}
+
+var hookTypes = []string{"PreSync", "Sync", "PostSync", "SyncFail", "PostDelete", "Skip"}
+
+var hookDeletePolicies = []string{"HookSucceeded", "HookFailed", "BeforeHookCreation"}
+
+// Verifies the values of the Argo CD annotations (sync options, sync wave, hook, hook deletion policy and
+// compare options) on the given rendered object
+func checkSyncAnnotations(obj *unstructured.Unstructured) error {
+	annotations := obj.GetAnnotations()
+	if v, found := annotations[syncOptionsAnnotation]; found {
+		if err := checkOptions(syncOptions, strings.Split(v, ",")...); err != nil {
+			return fmt.Errorf("invalid '%s' annotation: %w", syncOptionsAnnotation, err)
+		}
+	}
+	if v, found := annotations[syncWaveAnnotation]; found {
+		if _, err := strconv.Atoi(strings.TrimSpace(v)); err != nil {
+			return fmt.Errorf("invalid '%s' annotation: '%s' is not an integer", syncWaveAnnotation, v)
+		}
+	}
+	if v, found := annotations[hookAnnotation]; found {
+		if err := checkValues(hookTypes, strings.Split(v, ",")...); err != nil {
+			return fmt.Errorf("invalid '%s' annotation: %w", hookAnnotation, err)
+		}
+	}
+	if v, found := annotations[hookDeletePolicyAnnotation]; found {
+		if err := checkValues(hookDeletePolicies, strings.Split(v, ",")...); err != nil {
+			return fmt.Errorf("invalid '%s' annotation: %w", hookDeletePolicyAnnotation, err)
+		}
+	}
+	if v, found := annotations[compareOptionsAnnotation]; found {
+		if err := checkOptions(compareOptions, strings.Split(v, ",")...); err != nil {
+			return fmt.Errorf("invalid '%s' annotation: %w", compareOptionsAnnotation, err)
+		}
+	}
+	return nil
+}
+
+// Verifies the `spec.syncPolicy.syncOptions` of the given Application (or ApplicationSet template).
+// Options which contain a `{{...}}` placeholder are not checked.
+func (c *Checker) checkSyncPolicy(path, name string, policy *argocdv1alpha1.SyncPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	for _, o := range policy.SyncOptions {
+		if applications.IsTemplated(o) {
+			continue
+		}
+		if err := checkOptions(syncOptions, o); err != nil {
+			rpath, _ := filepath.Rel(c.baseDir, path)
+			return fmt.Errorf("invalid sync option in '%s' in %s: %w", name, rpath, err)
+		}
+	}
+	return nil
+}
+
+// verifies that each `key[=value]` option is known and has an accepted value
+func checkOptions(known map[string][]string, options ...string) error {
+	for _, o := range options {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			continue
+		}
+		key, value, hasValue := strings.Cut(o, "=")
+		values, found := known[key]
+		if !found {
+			keys := make([]string, 0, len(known))
+			for k := range known {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return unknown("option", key, keys)
+		}
+		if !hasValue {
+			if values != nil {
+				return fmt.Errorf("missing value for option '%s' (expected one of: %s)", key, strings.Join(values, ", "))
+			}
+			continue
+		}
+		if err := checkValues(values, value); err != nil {
+			return fmt.Errorf("invalid value for option '%s': %w", key, err)
+		}
+	}
+	return nil
+}
+
+// verifies that each value is one of the accepted values
+func checkValues(accepted []string, values ...string) error {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if !slices.Contains(accepted, v) {
+			return unknown("value", v, accepted)
+		}
+	}
+	return nil
+}
+
+// returns an error for the unknown name, with the closest candidates if any
+func unknown(kind, name string, candidates []string) error {
+	msg := fmt.Sprintf("unknown %s '%s'", kind, name)
+	if suggestions := suggest.Closest(name, candidates...); len(suggestions) > 0 {
+		return fmt.Errorf("%s (did you mean: %s?)", msg, strings.Join(suggestions, ", "))
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,165 @@
+package validation_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/test"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSyncAnnotations(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- job.yaml`,
+	}
+
+	t.Run("valid annotations", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/components/cookie/job.yaml": `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: cookie
+  annotations:
+    argocd.argoproj.io/sync-options: Prune=false,SkipDryRunOnMissingResource=true
+    argocd.argoproj.io/sync-wave: "-1"
+    argocd.argoproj.io/hook: PreSync,PostSync
+    argocd.argoproj.io/hook-delete-policy: BeforeHookCreation
+    argocd.argoproj.io/compare-options: IgnoreExtraneous,ServerSideDiff=true`,
+		})
+
+		// when
+		err := validation.CheckComponents(logger, afs, "/path/to", "components")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		for annotation, msg := range map[string]string{
+			`argocd.argoproj.io/sync-options: Prun=false`:                 `invalid 'argocd.argoproj.io/sync-options' annotation: unknown option 'Prun' (did you mean: Prune?)`,
+			`argocd.argoproj.io/sync-options: Prune=flase`:                `invalid 'argocd.argoproj.io/sync-options' annotation: invalid value for option 'Prune': unknown value 'flase' (did you mean: false?)`,
+			`argocd.argoproj.io/sync-options: Replace`:                    `invalid 'argocd.argoproj.io/sync-options' annotation: missing value for option 'Replace' (expected one of: true, false)`,
+			`argocd.argoproj.io/sync-wave: first`:                         `invalid 'argocd.argoproj.io/sync-wave' annotation: 'first' is not an integer`,
+			`argocd.argoproj.io/hook: PreSynch`:                           `invalid 'argocd.argoproj.io/hook' annotation: unknown value 'PreSynch' (did you mean: PreSync?)`,
+			`argocd.argoproj.io/hook-delete-policy: HookSucceed`:          `invalid 'argocd.argoproj.io/hook-delete-policy' annotation: unknown value 'HookSucceed' (did you mean: HookSucceeded?)`,
+			`argocd.argoproj.io/compare-options: IgnoreExtraneous,Ignore`: `invalid 'argocd.argoproj.io/compare-options' annotation: unknown option 'Ignore'`,
+		} {
+			t.Run(annotation, func(t *testing.T) {
+				// given
+				logger := log.New(os.Stdout)
+				afs := test.NewFS(t, files, map[string]string{
+					"/path/to/components/cookie/job.yaml": `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: cookie
+  annotations:
+    ` + annotation,
+				})
+
+				// when
+				err := validation.CheckComponents(logger, afs, "/path/to", "components")
+
+				// then
+				require.EqualError(t, err, "Job/cookie in components/cookie: "+msg)
+			})
+		}
+	})
+}
+
+func TestCheckSyncPolicy(t *testing.T) {
+
+	// given
+	files := map[string]string{
+		"/path/to/components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1`,
+	}
+
+	t.Run("valid sync options", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespace=true
+    - PrunePropagationPolicy=foreground`,
+		})
+
+		// when
+		err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown sync option", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - CreateNamespaces=true`,
+		})
+
+		// when
+		err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+		// then
+		require.EqualError(t, err, "invalid sync option in 'app-cookie' in apps/app-cookie.yaml: unknown option 'CreateNamespaces' (did you mean: CreateNamespace?)")
+	})
+
+	t.Run("invalid sync option value", func(t *testing.T) {
+		// given
+		logger := log.New(os.Stdout)
+		afs := test.NewFS(t, files, map[string]string{
+			"/path/to/apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie
+  syncPolicy:
+    syncOptions:
+    - PrunePropagationPolicy=foregroud`,
+		})
+
+		// when
+		err := validation.CheckApplications(logger, afs, "/path/to", "apps")
+
+		// then
+		require.EqualError(t, err, "invalid sync option in 'app-cookie' in apps/app-cookie.yaml: invalid value for option 'PrunePropagationPolicy': unknown value 'foregroud' (did you mean: foreground?)")
+	})
+}
@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"context"
+	iofs "io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/kustomizations"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// delay to wait for other changes before running the checks (eg: when an editor saves multiple files at once)
+const debounce = 300 * time.Millisecond
+
+// Watch runs the checks on the given apps and components, then watches the base directory for changes
+// and re-runs the checks on the kustomizations and Applications affected by the changed files, until the context is done.
+// The in-memory filesystems used by `kustomize build` are updated with the changed files only.
+func (c *Checker) Watch(ctx context.Context, apps, components []string) error {
+	roots := []string{}
+	for _, p := range append(apps, components...) {
+		roots = append(roots, filepath.Join(c.baseDir, p))
+	}
+	graph, err := kustomizations.NewGraph(c.logger, c.afs, c.baseDir, append(apps, components...)...)
+	if err != nil {
+		return err
+	}
+	c.run(apps, components)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := c.watchDirs(watcher, c.baseDir); err != nil {
+		return err
+	}
+	c.logger.Info("👀 watching for changes", "base-dir", c.baseDir)
+
+	changes := map[string]bool{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if c.hidden(e.Name) || e.Has(fsnotify.Chmod) {
+				continue
+			}
+			c.logger.Debug("file changed", "path", e.Name, "op", e.Op.String())
+			if e.Has(fsnotify.Create) {
+				if info, err := c.afs.Stat(e.Name); err == nil && info.IsDir() {
+					if err := c.watchDirs(watcher, e.Name); err != nil {
+						return err
+					}
+				}
+			}
+			changes[e.Name] = true
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			c.logger.Error("failed to watch files", "err", err)
+		case <-timer.C:
+			files := make([]string, 0, len(changes))
+			for f := range changes {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+			changes = map[string]bool{}
+			if err := c.refresh(graph, roots, files...); err != nil {
+				c.logger.Error("failed to refresh the in-memory filesystem", "err", err)
+				continue
+			}
+			affected := graph.Affected(files...)
+			c.logger.Info("🔎 files changed", "files", strings.Join(files, ","), "kustomizations", len(affected))
+			WithSelection(append(affected, files...)...)(c)
+			c.run(apps, components)
+		}
+	}
+}
+
+// runs the checks on the given apps and components, and prints a short summary
+func (c *Checker) run(apps, components []string) {
+	start := time.Now()
+	if err := c.CheckApplications(apps...); err != nil {
+		c.logger.Error("❌ checks failed", "err", err)
+		return
+	}
+	if err := c.CheckComponents(components...); err != nil {
+		c.logger.Error("❌ checks failed", "err", err)
+		return
+	}
+	if err := c.CheckSources(); err != nil {
+		c.logger.Error("❌ checks failed", "err", err)
+		return
+	}
+	c.logger.Info("✅ checks passed", "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// updates the in-memory filesystems and the graph of kustomizations with the given changed files,
+// and resets the image inventory which is recorded again by the next checks
+func (c *Checker) refresh(graph *kustomizations.Graph, roots []string, files ...string) error {
+	if c.imageInventory != nil {
+		*c.imageInventory = []images.Inventory{}
+	}
+	for _, f := range files {
+		for root, fsys := range c.fsys {
+			if f == root || kustomizations.Contains(root, f) {
+				if err := UpdateInMemoryFS(c.logger, c.afs, fsys, f); err != nil {
+					return err
+				}
+			}
+		}
+		for _, root := range roots {
+			if f != root && !kustomizations.Contains(root, f) {
+				continue
+			}
+			// the changed path may be a kustomization file or a directory (with a kustomization file)
+			if f != root {
+				if err := graph.Add(c.logger, c.afs, filepath.Dir(f)); err != nil {
+					return err
+				}
+			}
+			if err := graph.Add(c.logger, c.afs, f); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// adds the given directory and all its subdirectories to the watcher (fsnotify does not watch recursively)
+func (c *Checker) watchDirs(watcher *fsnotify.Watcher, dir string) error {
+	return c.afs.Walk(dir, func(path string, info iofs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && c.hidden(path) {
+			return filepath.SkipDir
+		}
+		c.logger.Debug("watching directory", "path", path)
+		return watcher.Add(path)
+	})
+}
+
+// returns true if the given path is (in) a hidden file or directory of the base directory, such as `.git`
+func (c *Checker) hidden(path string) bool {
+	rel, err := filepath.Rel(c.baseDir, path)
+	if err != nil {
+		return false
+	}
+	for _, e := range strings.Split(filepath.ToSlash(rel), "/") {
+		if strings.HasPrefix(e, ".") && e != "." && e != ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package validation_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/images"
+	"github.com/codeready-toolchain/sandbox-argocd/pkg/validation"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+
+	// given
+	baseDir := t.TempDir()
+	for path, data := range map[string]string{
+		"apps/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- app-cookie.yaml`,
+		"apps/app-cookie.yaml": `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: app-cookie
+spec:
+  destination:
+    server: https://kubernetes.default.svc
+  project: default
+  source:
+    path: components/cookie`,
+		"components/cookie/kustomization.yaml": `kind: Kustomization
+apiVersion: kustomize.config.k8s.io/v1beta1
+resources:
+- deployment.yaml`,
+		"components/cookie/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/sandbox/cookie:v1.0.0`,
+	} {
+		err := os.MkdirAll(filepath.Join(baseDir, filepath.Dir(path)), 0755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(baseDir, path), []byte(data), 0600)
+		require.NoError(t, err)
+	}
+	out := &syncBuffer{}
+	logger := log.New(out)
+	afs := afero.Afero{
+		Fs: afero.NewOsFs(),
+	}
+	inventory := []images.Inventory{}
+	checker := validation.NewChecker(logger, afs, baseDir, validation.WithImageInventory(&inventory))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	// when
+	go func() {
+		done <- checker.Watch(ctx, []string{"apps"}, []string{"components"})
+	}()
+
+	// then
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "watching for changes")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, strings.Count(out.String(), "checks passed"))
+
+	// when
+	err := os.WriteFile(filepath.Join(baseDir, "components/cookie/deployment.yaml"), []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cookie
+spec:
+  template:
+    spec:
+      containers:
+      - name: cookie
+        image: quay.io/sandbox/cookie:v1.1.0`), 0600)
+	require.NoError(t, err)
+
+	// then
+	require.Eventually(t, func() bool {
+		return strings.Count(out.String(), "checks passed") == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, out.String(), "files changed")
+
+	cancel()
+	require.NoError(t, <-done)
+	// the image inventory is recorded again after the change
+	assert.Equal(t, []images.Inventory{
+		{
+			Application: "app-cookie",
+			Path:        "apps/app-cookie.yaml",
+			Images: []images.Image{
+				{
+					Reference: "quay.io/sandbox/cookie:v1.1.0",
+					Workload:  "Deployment/cookie",
+					Container: "cookie",
+				},
+			},
+		},
+	}, inventory)
+}
+
+// a buffer which can be written by the watcher and read by the test concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}